@@ -0,0 +1,172 @@
+package achievements
+
+import (
+	"log"
+	"time"
+	"time-tracker/goals"
+	"time-tracker/models"
+	"time-tracker/stats"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// facts are the user statistics achievements are evaluated against
+// Each one is loaded lazily, only if a pending achievement needs it
+type facts struct {
+	tx     *gorm.DB
+	userID uuid.UUID
+	loc    *time.Location
+
+	sessions      *int
+	currentStreak *int
+	maxDaySeconds *int64
+}
+
+func (f *facts) totalSessions() (int, error) {
+	if f.sessions == nil {
+		var sessions int
+		if err := f.tx.Model(&models.DailyUserTotal{}).
+			Select("COALESCE(SUM(sessions), 0)").
+			Where("user_id = ?", f.userID).
+			Scan(&sessions).Error; err != nil {
+			return 0, err
+		}
+		f.sessions = &sessions
+	}
+	return *f.sessions, nil
+}
+
+func (f *facts) streak() (int, error) {
+	if f.currentStreak == nil {
+		streaks, err := goals.Streaks(f.tx, []uuid.UUID{f.userID}, map[uuid.UUID]*time.Location{f.userID: f.loc}, time.Now())
+		if err != nil {
+			return 0, err
+		}
+		streak := streaks[f.userID].CurrentStreak
+		f.currentStreak = &streak
+	}
+	return *f.currentStreak, nil
+}
+
+func (f *facts) bestDaySeconds() (int64, error) {
+	if f.maxDaySeconds == nil {
+		var seconds int64
+		if err := f.tx.Raw(`
+			SELECT COALESCE(MAX(day_seconds), 0)
+			FROM (
+				SELECT SUM(seconds) AS day_seconds
+				FROM daily_user_totals
+				WHERE user_id = ?
+				GROUP BY local_date
+			) days
+		`, f.userID).Scan(&seconds).Error; err != nil {
+			return 0, err
+		}
+		f.maxDaySeconds = &seconds
+	}
+	return *f.maxDaySeconds, nil
+}
+
+// met reports whether the achievement's criteria hold for the user after the given entry
+func met(achievement models.Achievement, entry models.TimeEntry, f *facts) (bool, error) {
+	switch achievement.Criteria {
+	case models.CriteriaTotalSessions:
+		sessions, err := f.totalSessions()
+		return sessions >= achievement.Threshold, err
+	case models.CriteriaStreakDays:
+		streak, err := f.streak()
+		return streak >= achievement.Threshold, err
+	case models.CriteriaDayHours:
+		seconds, err := f.bestDaySeconds()
+		return seconds >= int64(achievement.Threshold)*3600, err
+	case models.CriteriaStartBeforeHour:
+		return entry.StartTime.In(f.loc).Hour() < achievement.Threshold, nil
+	case models.CriteriaStartAfterHour:
+		return entry.StartTime.In(f.loc).Hour() >= achievement.Threshold, nil
+	default:
+		log.Printf("Unknown achievement criteria %q for achievement %s", achievement.Criteria, achievement.Code)
+		return false, nil
+	}
+}
+
+// Evaluate unlocks every achievement the user newly qualifies for after stopping an entry
+// Call it after the stop was committed, in its own transaction, so a failure here never undoes the stop
+func Evaluate(tx *gorm.DB, userID uuid.UUID, entry models.TimeEntry) ([]models.Achievement, error) {
+	// Only look at achievements the user has not unlocked yet
+	var pending []models.Achievement
+	if err := tx.Where("id NOT IN (?)", tx.Model(&models.UserAchievement{}).Select("achievement_id").Where("user_id = ?", userID)).
+		Order("sort_order").
+		Find(&pending).Error; err != nil {
+		return nil, err
+	}
+
+	f := &facts{tx: tx, userID: userID, loc: stats.UserLocation(tx, userID)}
+	now := time.Now()
+
+	var unlocked []models.Achievement
+	for _, achievement := range pending {
+		ok, err := met(achievement, entry, f)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserAchievement{
+			UserID:        userID,
+			AchievementID: achievement.ID,
+			UnlockedAt:    now,
+		})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		// A concurrent stop already unlocked it and reports it itself
+		if result.RowsAffected == 0 {
+			continue
+		}
+		unlocked = append(unlocked, achievement)
+	}
+
+	return unlocked, nil
+}
+
+// ForUser returns every achievement with the user's unlock status, in display order
+func ForUser(db *gorm.DB, userID uuid.UUID) ([]models.AchievementResponse, error) {
+	var all []models.Achievement
+	if err := db.Order("sort_order").Find(&all).Error; err != nil {
+		return nil, err
+	}
+
+	var owned []models.UserAchievement
+	if err := db.Where("user_id = ?", userID).Find(&owned).Error; err != nil {
+		return nil, err
+	}
+	unlockedAt := make(map[uuid.UUID]time.Time, len(owned))
+	for _, userAchievement := range owned {
+		unlockedAt[userAchievement.AchievementID] = userAchievement.UnlockedAt
+	}
+
+	responses := make([]models.AchievementResponse, 0, len(all))
+	for _, achievement := range all {
+		response := ToResponse(achievement)
+		if at, ok := unlockedAt[achievement.ID]; ok {
+			response.Unlocked = true
+			response.UnlockedAt = &at
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// ToResponse converts an achievement definition to its response format
+func ToResponse(achievement models.Achievement) models.AchievementResponse {
+	return models.AchievementResponse{
+		Code:        achievement.Code,
+		Name:        achievement.Name,
+		Description: achievement.Description,
+		Icon:        achievement.Icon,
+	}
+}
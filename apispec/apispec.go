@@ -0,0 +1,105 @@
+package apispec
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// pathParam matches a templated path segment such as {id}
+var pathParam = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// Spec is a loaded OpenAPI document with its operations indexed by Gin route
+type Spec struct {
+	doc    *openapi3.T
+	routes map[string]*routers.Route // Keyed by method and Gin path, e.g. "GET /api/v1/projects/:id"
+}
+
+// Load reads and validates the OpenAPI document at path
+func Load(path string) (*Spec, error) {
+	// Keep violations to one line instead of dumping the whole schema and value
+	openapi3.SchemaErrorDetailsDisabled = true
+
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", path, err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	spec := &Spec{doc: doc, routes: map[string]*routers.Route{}}
+	for _, path := range doc.Paths.InMatchingOrder() {
+		item := doc.Paths.Value(path)
+
+		// Path-level servers override the document's, e.g. for routes outside /api/v1
+		servers := doc.Servers
+		if len(item.Servers) > 0 {
+			servers = item.Servers
+		}
+		base, err := basePath(servers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid servers for %s: %w", path, err)
+		}
+
+		for method, operation := range item.Operations() {
+			spec.routes[key(method, base+ginPath(path))] = &routers.Route{
+				Spec:      doc,
+				Path:      path,
+				PathItem:  item,
+				Method:    method,
+				Operation: operation,
+			}
+		}
+	}
+	return spec, nil
+}
+
+// Route returns the operation documented for a Gin route
+func (s *Spec) Route(method, ginPath string) (*routers.Route, bool) {
+	route, ok := s.routes[key(method, ginPath)]
+	return route, ok
+}
+
+// Operations lists every documented operation as "METHOD /gin/path", sorted
+func (s *Spec) Operations() []string {
+	operations := make([]string, 0, len(s.routes))
+	for operation := range s.routes {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+func key(method, ginPath string) string {
+	return strings.ToUpper(method) + " " + ginPath
+}
+
+// ginPath turns an OpenAPI path template into a Gin route, e.g. /projects/{id} into /projects/:id
+func ginPath(path string) string {
+	return pathParam.ReplaceAllString(path, ":$1")
+}
+
+// basePath returns the path all servers share, e.g. /api/v1
+func basePath(servers openapi3.Servers) (string, error) {
+	base := ""
+	for i, server := range servers {
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			return "", err
+		}
+		path := strings.TrimSuffix(u.Path, "/")
+		if i > 0 && path != base {
+			return "", fmt.Errorf("servers disagree on the base path (%q and %q)", base, path)
+		}
+		base = path
+	}
+	return base, nil
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time-tracker/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Context identifies who made a change and the request that made it
+type Context struct {
+	ActorID   *uuid.UUID
+	RequestID string
+	IP        string
+	UserAgent string
+}
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx that carries the audit context,
+// for changes recorded below the handlers
+func WithContext(ctx context.Context, auditCtx Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, auditCtx)
+}
+
+// FromContext returns the audit context carried by ctx, or an empty one
+func FromContext(ctx context.Context) Context {
+	auditCtx, _ := ctx.Value(contextKey{}).(Context)
+	return auditCtx
+}
+
+// ignoredFields are bookkeeping fields that change on every update
+var ignoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// Record writes an audit log entry for a change to an entity
+// Pass a nil before on create and a nil after on delete. Call it inside the
+// transaction that makes the change so the change and its audit entry commit together
+func Record(tx *gorm.DB, ctx Context, entityType string, entityID uuid.UUID, before, after interface{}) error {
+	action := models.AuditActionUpdate
+	if before == nil {
+		action = models.AuditActionCreate
+	} else if after == nil {
+		action = models.AuditActionDelete
+	}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.AuditLog{
+		ActorID:    ctx.ActorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    encoded,
+		RequestID:  ctx.RequestID,
+		IP:         ctx.IP,
+		UserAgent:  ctx.UserAgent,
+	}).Error
+}
+
+// Diff compares the JSON representations of two versions of an entity field by field
+// Nested objects such as preloaded relations are skipped; a nil version has no fields
+func Diff(before, after interface{}) (map[string]models.AuditChange, error) {
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.AuditChange{}
+	for name, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[name]) {
+			changes[name] = models.AuditChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, seen := beforeFields[name]; !seen && value != nil {
+			changes[name] = models.AuditChange{Before: nil, After: value}
+		}
+	}
+	return changes, nil
+}
+
+// fields returns the top-level JSON fields of an entity, leaving out nested objects and ignored fields
+func fields(entity interface{}) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	if entity == nil {
+		return result, nil
+	}
+
+	encoded, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+
+	for name, value := range decoded {
+		if _, nested := value.(map[string]interface{}); nested || ignoredFields[name] {
+			continue
+		}
+		result[name] = value
+	}
+	return result, nil
+}
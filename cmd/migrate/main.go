@@ -6,16 +6,18 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time-tracker/config"
 	"time-tracker/database"
-
-	"github.com/joho/godotenv"
 )
 
 func main() {
-	// Load environment variables
-	err := godotenv.Load()
+	// Load settings; migrations only need the database
+	cfg, err := config.Read()
 	if err != nil {
-		log.Println("Warning: .env file not found, using system environment variables")
+		log.Fatal("Invalid configuration:\n", err)
+	}
+	if err := cfg.Database.Validate(); err != nil {
+		log.Fatal("Invalid configuration:\n", err)
 	}
 
 	// Define command line flags
@@ -28,7 +30,7 @@ func main() {
 
 	// Show current status
 	if *status {
-		showStatus()
+		showStatus(cfg.Database.URL)
 		return
 	}
 
@@ -39,7 +41,7 @@ func main() {
 			log.Fatal("Invalid version number:", err)
 		}
 
-		err = database.MigrateToVersion(uint(v))
+		err = database.MigrateToVersion(cfg.Database.URL, uint(v))
 		if err != nil {
 			log.Fatal("Migration failed:", err)
 		}
@@ -56,14 +58,14 @@ func main() {
 		os.Exit(1)
 	}
 
-	err = database.RunMigrations(*direction)
+	err = database.RunMigrations(cfg.Database.URL, *direction)
 	if err != nil {
 		log.Fatal("Migration failed:", err)
 	}
 }
 
-func showStatus() {
-	version, dirty, err := database.GetCurrentVersion()
+func showStatus(databaseURL string) {
+	version, dirty, err := database.GetCurrentVersion(databaseURL)
 	if err != nil {
 		log.Fatal("Failed to get migration status:", err)
 	}
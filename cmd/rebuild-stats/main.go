@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time-tracker/config"
+	"time-tracker/database"
+	"time-tracker/stats"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func main() {
+	// Load settings; rebuilding only needs the database
+	cfg, err := config.Read()
+	if err != nil {
+		log.Fatal("Invalid configuration:\n", err)
+	}
+	if err := cfg.Database.Validate(); err != nil {
+		log.Fatal("Invalid configuration:\n", err)
+	}
+
+	// Define command line flags
+	user := flag.String("user", "", "Only rebuild the daily totals of this user ID")
+	flag.Parse()
+
+	// Connect to database
+	db := database.Connect(cfg.Database)
+
+	// Rebuild a single user
+	if *user != "" {
+		userID, err := uuid.Parse(*user)
+		if err != nil {
+			log.Fatal("Invalid user ID:", err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			return stats.RebuildUser(tx, userID)
+		})
+		if err != nil {
+			log.Fatal("Rebuild failed:", err)
+		}
+		log.Printf("Rebuilt daily totals for user %s", userID)
+		return
+	}
+
+	// Rebuild everyone
+	if err := stats.RebuildAll(db); err != nil {
+		log.Fatal("Rebuild failed:", err)
+	}
+	log.Println("Daily totals rebuilt successfully")
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+	"time-tracker/ratelimit"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Authentication modes
+const (
+	AuthModeVerify      = "verify"       // Tokens must be signed with a configured key (default)
+	AuthModeInsecureDev = "insecure-dev" // Only development tokens are accepted, for local development only
+)
+
+// Storage backends
+const (
+	StorageBackendSupabase = "supabase"
+)
+
+// OpenAPI validation modes
+const (
+	OpenAPIModeOff    = "off"    // Requests and responses are not checked
+	OpenAPIModeLog    = "log"    // Violations are logged and requests are served unchanged (default)
+	OpenAPIModeStrict = "strict" // Invalid requests get a 400 and invalid responses are replaced with a 500
+)
+
+// Config holds all application settings
+type Config struct {
+	Env             string          `yaml:"env"`
+	Server          Server          `yaml:"server"`
+	Database        Database        `yaml:"database"`
+	Supabase        Supabase        `yaml:"supabase"`
+	Storage         Storage         `yaml:"storage"`
+	Auth            Auth            `yaml:"auth"`
+	CORS            CORS            `yaml:"cors"`
+	RateLimit       RateLimit       `yaml:"rate_limit"`
+	AccountDeletion AccountDeletion `yaml:"account_deletion"`
+	OpenAPI         OpenAPI         `yaml:"openapi"`
+}
+
+// Server configures the HTTP server
+type Server struct {
+	Port         string        `yaml:"port"`
+	GinMode      string        `yaml:"gin_mode"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+
+	// Proxies (IPs or CIDRs) whose X-Forwarded-For and X-Real-IP headers name the client
+	// Without any, the client is the connection's remote address, so those headers cannot be spoofed
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// Database configures the PostgreSQL connection pool
+type Database struct {
+	URL             string        `yaml:"url"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+}
+
+// Supabase holds the Supabase project credentials
+type Supabase struct {
+	URL            string `yaml:"url"`
+	AnonKey        string `yaml:"anon_key"`
+	ServiceRoleKey string `yaml:"service_role_key"`
+}
+
+// Storage configures where profile pictures are stored
+type Storage struct {
+	Backend       string        `yaml:"backend"`
+	Bucket        string        `yaml:"bucket"`
+	MaxUploadSize int64         `yaml:"max_upload_size"` // Bytes
+	Timeout       time.Duration `yaml:"timeout"`
+}
+
+// Auth configures how requests are authenticated and who is an admin
+type Auth struct {
+	Mode       string   `yaml:"mode"`
+	AdminRoles []string `yaml:"admin_roles"` // JWT roles allowed to use admin endpoints
+	JWT        JWT      `yaml:"jwt"`
+}
+
+// JWT configures how access tokens are verified
+type JWT struct {
+	Secret              string        `yaml:"secret"`     // HS256 shared secret, empty to reject HS256 tokens
+	JWKSURL             string        `yaml:"jwks_url"`   // JWKS endpoint for RS256/ES256 keys, empty to reject them
+	Issuer              string        `yaml:"issuer"`     // Required iss claim, empty to accept any issuer
+	Audience            string        `yaml:"audience"`   // Required aud claim
+	ClockSkew           time.Duration `yaml:"clock_skew"` // Leeway for exp, nbf and iat
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval"`
+}
+
+// CORS configures which browser origins may call the API
+type CORS struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"` // Exact origins, "*" for any, or wildcard subdomains such as https://*.example.com
+	AllowedMethods   []string      `yaml:"allowed_methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers"` // Response headers readable by browser scripts
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"` // How long browsers may cache preflight responses
+}
+
+// RateLimit sets the limit of each route group; groups without their own limit use Default
+type RateLimit struct {
+	Default ratelimit.Limit            `yaml:"default"`
+	Groups  map[string]ratelimit.Limit `yaml:"groups"`
+}
+
+// Limit returns the limit of a route group
+func (r RateLimit) Limit(group string) ratelimit.Limit {
+	if limit, ok := r.Groups[group]; ok {
+		return limit
+	}
+	return r.Default
+}
+
+// AccountDeletion configures scheduled account deletion
+type AccountDeletion struct {
+	GraceDays int `yaml:"grace_days"` // Days during which a scheduled deletion can be cancelled
+}
+
+// GracePeriod returns how long a scheduled deletion can still be cancelled
+func (a AccountDeletion) GracePeriod() time.Duration {
+	return time.Duration(a.GraceDays) * 24 * time.Hour
+}
+
+// OpenAPI configures validation of requests and responses against the API specification
+type OpenAPI struct {
+	SpecPath string `yaml:"spec_path"`
+	Mode     string `yaml:"mode"`
+
+	// Also check responses, which buffers them in strict mode; meant for tests and staging
+	ValidateResponses bool `yaml:"validate_responses"`
+}
+
+// Default returns the settings used for everything that is not configured
+func Default() *Config {
+	return &Config{
+		Env: "development",
+		Server: Server{
+			Port:         "8080",
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 30 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		},
+		Database: Database{
+			MaxIdleConns:    10,
+			MaxOpenConns:    100,
+			ConnMaxLifetime: time.Hour,
+		},
+		Storage: Storage{
+			Backend:       StorageBackendSupabase,
+			Bucket:        "profile-pictures",
+			MaxUploadSize: 5 * 1024 * 1024, // 5MB
+			Timeout:       30 * time.Second,
+		},
+		Auth: Auth{
+			Mode:       AuthModeVerify,
+			AdminRoles: []string{"admin", "service_role"},
+			JWT: JWT{
+				Audience:            "authenticated", // Supabase's audience for signed-in users
+				ClockSkew:           30 * time.Second,
+				JWKSRefreshInterval: 10 * time.Minute,
+			},
+		},
+		CORS: CORS{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "X-Request-ID"},
+			ExposedHeaders: []string{
+				"X-Request-ID", "X-Total-Count",
+				"RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After",
+			},
+			MaxAge: 10 * time.Minute,
+		},
+		RateLimit: RateLimit{
+			Default: ratelimit.Limit{Requests: 120, Period: time.Minute},
+			Groups: map[string]ratelimit.Limit{
+				"ip":          {Requests: 300, Period: time.Minute}, // All API requests per client IP, before authentication
+				"leaderboard": {Requests: 30, Period: time.Minute},
+				"dev_token":   {Requests: 10, Period: time.Minute},
+			},
+		},
+		AccountDeletion: AccountDeletion{
+			GraceDays: 7,
+		},
+		OpenAPI: OpenAPI{
+			SpecPath: "openapi.yaml",
+			Mode:     OpenAPIModeLog,
+		},
+	}
+}
+
+// Read loads the settings without validating them
+// Values come from the defaults, then the YAML file named by CONFIG_FILE, then the environment;
+// a .env file is loaded into the environment first without overriding variables that are already set
+func Read() (*Config, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found, using system environment variables")
+	}
+
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.readEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Load reads and validates the settings
+func Load() (*Config, error) {
+	cfg, err := Read()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"time-tracker/ratelimit"
+)
+
+// rateLimitPrefix starts the variables holding per-group rate limits, e.g. RATE_LIMIT_LEADERBOARD
+const rateLimitPrefix = "RATE_LIMIT_"
+
+// envReader applies environment variables on top of the settings, collecting parse errors
+type envReader struct {
+	errs []error
+}
+
+func (cfg *Config) readEnv() error {
+	r := &envReader{}
+
+	r.string("ENV", &cfg.Env)
+
+	r.string("PORT", &cfg.Server.Port)
+	r.string("GIN_MODE", &cfg.Server.GinMode)
+	r.duration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	r.duration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	r.duration("SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	r.list("TRUSTED_PROXIES", &cfg.Server.TrustedProxies)
+
+	r.string("DATABASE_URL", &cfg.Database.URL)
+	r.int("DB_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns)
+	r.int("DB_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns)
+	r.duration("DB_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime)
+
+	r.string("SUPABASE_URL", &cfg.Supabase.URL)
+	r.string("SUPABASE_ANON_KEY", &cfg.Supabase.AnonKey)
+	r.string("SUPABASE_SERVICE_ROLE_KEY", &cfg.Supabase.ServiceRoleKey)
+
+	r.string("STORAGE_BACKEND", &cfg.Storage.Backend)
+	r.string("STORAGE_BUCKET", &cfg.Storage.Bucket)
+	r.int64("STORAGE_MAX_UPLOAD_SIZE", &cfg.Storage.MaxUploadSize)
+	r.duration("STORAGE_TIMEOUT", &cfg.Storage.Timeout)
+
+	r.string("AUTH_MODE", &cfg.Auth.Mode)
+	r.list("ADMIN_ROLES", &cfg.Auth.AdminRoles)
+	r.string("JWT_SECRET", &cfg.Auth.JWT.Secret)
+	r.string("JWT_JWKS_URL", &cfg.Auth.JWT.JWKSURL)
+	r.string("JWT_ISSUER", &cfg.Auth.JWT.Issuer)
+	r.string("JWT_AUDIENCE", &cfg.Auth.JWT.Audience)
+	r.duration("JWT_CLOCK_SKEW", &cfg.Auth.JWT.ClockSkew)
+	r.duration("JWT_JWKS_REFRESH_INTERVAL", &cfg.Auth.JWT.JWKSRefreshInterval)
+
+	r.list("CORS_ALLOWED_ORIGINS", &cfg.CORS.AllowedOrigins)
+	r.list("CORS_ALLOWED_METHODS", &cfg.CORS.AllowedMethods)
+	r.list("CORS_ALLOWED_HEADERS", &cfg.CORS.AllowedHeaders)
+	r.list("CORS_EXPOSED_HEADERS", &cfg.CORS.ExposedHeaders)
+	r.bool("CORS_ALLOW_CREDENTIALS", &cfg.CORS.AllowCredentials)
+	r.duration("CORS_MAX_AGE", &cfg.CORS.MaxAge)
+
+	r.rateLimits(&cfg.RateLimit)
+
+	r.int("ACCOUNT_DELETION_GRACE_DAYS", &cfg.AccountDeletion.GraceDays)
+
+	r.string("OPENAPI_SPEC_PATH", &cfg.OpenAPI.SpecPath)
+	r.string("OPENAPI_VALIDATION", &cfg.OpenAPI.Mode)
+	r.bool("OPENAPI_VALIDATE_RESPONSES", &cfg.OpenAPI.ValidateResponses)
+
+	return errors.Join(r.errs...)
+}
+
+func (r *envReader) fail(name, value, expected string) {
+	r.errs = append(r.errs, fmt.Errorf("%s=%q is not a valid %s", name, value, expected))
+}
+
+func (r *envReader) string(name string, target *string) {
+	if value := os.Getenv(name); value != "" {
+		*target = strings.TrimSpace(value)
+	}
+}
+
+func (r *envReader) int(name string, target *int) {
+	if value := os.Getenv(name); value != "" {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			r.fail(name, value, "integer")
+			return
+		}
+		*target = n
+	}
+}
+
+func (r *envReader) int64(name string, target *int64) {
+	if value := os.Getenv(name); value != "" {
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			r.fail(name, value, "integer")
+			return
+		}
+		*target = n
+	}
+}
+
+func (r *envReader) bool(name string, target *bool) {
+	if value := os.Getenv(name); value != "" {
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			r.fail(name, value, "boolean")
+			return
+		}
+		*target = b
+	}
+}
+
+func (r *envReader) duration(name string, target *time.Duration) {
+	if value := os.Getenv(name); value != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			r.fail(name, value, "duration such as 30s or 10m")
+			return
+		}
+		*target = d
+	}
+}
+
+// list reads a comma-separated list
+func (r *envReader) list(name string, target *[]string) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*target = items
+}
+
+// rateLimits reads RATE_LIMIT_DEFAULT and RATE_LIMIT_<GROUP> limits such as RATE_LIMIT_LEADERBOARD=30/1m
+func (r *envReader) rateLimits(target *RateLimit) {
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		group, ok := strings.CutPrefix(name, rateLimitPrefix)
+		if !ok || value == "" {
+			continue
+		}
+
+		limit, err := ratelimit.ParseLimit(value)
+		if err != nil {
+			r.fail(name, value, "rate limit such as 60/1m or off")
+			continue
+		}
+		if group == "DEFAULT" {
+			target.Default = limit
+			continue
+		}
+		if target.Groups == nil {
+			target.Groups = map[string]ratelimit.Limit{}
+		}
+		target.Groups[strings.ToLower(group)] = limit
+	}
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time-tracker/ratelimit"
+)
+
+// Validate checks that required settings are present and all settings are usable
+// Every problem is reported, not just the first one
+func (cfg *Config) Validate() error {
+	return errors.Join(
+		cfg.Server.Validate(),
+		cfg.Database.Validate(),
+		cfg.Supabase.Validate(),
+		cfg.Storage.Validate(),
+		cfg.Auth.Validate(cfg.Env),
+		cfg.CORS.Validate(),
+		cfg.RateLimit.Validate(),
+		cfg.AccountDeletion.Validate(),
+		cfg.OpenAPI.Validate(),
+	)
+}
+
+// Validate checks the HTTP server settings
+func (s Server) Validate() error {
+	var errs []error
+	if port, err := strconv.Atoi(s.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT=%q must be a port number between 1 and 65535", s.Port))
+	}
+	if !slices.Contains([]string{"", "debug", "release", "test"}, s.GinMode) {
+		errs = append(errs, fmt.Errorf("GIN_MODE=%q must be debug, release or test", s.GinMode))
+	}
+	if s.ReadTimeout < 0 || s.WriteTimeout < 0 || s.IdleTimeout < 0 {
+		errs = append(errs, errors.New("SERVER_READ_TIMEOUT, SERVER_WRITE_TIMEOUT and SERVER_IDLE_TIMEOUT cannot be negative"))
+	}
+	for _, proxy := range s.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES: %q is not an IP address or CIDR range", proxy))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the database settings
+func (d Database) Validate() error {
+	var errs []error
+	if d.URL == "" {
+		errs = append(errs, errors.New("DATABASE_URL is required"))
+	}
+	if d.MaxOpenConns < 1 {
+		errs = append(errs, fmt.Errorf("DB_MAX_OPEN_CONNS=%d must be at least 1", d.MaxOpenConns))
+	}
+	if d.MaxIdleConns < 0 || d.MaxIdleConns > d.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS=%d must be between 0 and DB_MAX_OPEN_CONNS", d.MaxIdleConns))
+	}
+	if d.ConnMaxLifetime < 0 {
+		errs = append(errs, errors.New("DB_CONN_MAX_LIFETIME cannot be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the Supabase credentials
+func (s Supabase) Validate() error {
+	var errs []error
+	if s.URL == "" {
+		errs = append(errs, errors.New("SUPABASE_URL is required"))
+	} else if err := validateURL(s.URL); err != nil {
+		errs = append(errs, fmt.Errorf("SUPABASE_URL: %w", err))
+	}
+	if s.AnonKey == "" {
+		errs = append(errs, errors.New("SUPABASE_ANON_KEY is required"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the storage settings
+func (s Storage) Validate() error {
+	var errs []error
+	if s.Backend != StorageBackendSupabase {
+		errs = append(errs, fmt.Errorf("STORAGE_BACKEND=%q is not supported (use %s)", s.Backend, StorageBackendSupabase))
+	}
+	if s.Bucket == "" {
+		errs = append(errs, errors.New("STORAGE_BUCKET cannot be empty"))
+	}
+	if s.MaxUploadSize < 1 {
+		errs = append(errs, fmt.Errorf("STORAGE_MAX_UPLOAD_SIZE=%d must be at least 1 byte", s.MaxUploadSize))
+	}
+	if s.Timeout < 0 {
+		errs = append(errs, errors.New("STORAGE_TIMEOUT cannot be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the authentication settings
+// Insecure development mode is refused in production and alongside a real JWT_SECRET,
+// and the default mode needs a JWT_SECRET or JWT_JWKS_URL to verify tokens with
+func (a Auth) Validate(env string) error {
+	var errs []error
+	switch a.Mode {
+	case AuthModeInsecureDev:
+		if env == "production" {
+			errs = append(errs, errors.New("AUTH_MODE=insecure-dev cannot be used with ENV=production"))
+		}
+		// Development tokens are signed with a public key, so a real secret must not be around
+		if a.JWT.Secret != "" {
+			errs = append(errs, errors.New("AUTH_MODE=insecure-dev cannot be used with JWT_SECRET set"))
+		}
+	case AuthModeVerify:
+		if a.JWT.Secret == "" && a.JWT.JWKSURL == "" {
+			errs = append(errs, errors.New("no token verification keys configured: set JWT_SECRET or JWT_JWKS_URL, or AUTH_MODE=insecure-dev for local development"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown AUTH_MODE %q (use %s or %s)", a.Mode, AuthModeVerify, AuthModeInsecureDev))
+	}
+
+	if a.JWT.JWKSURL != "" {
+		if err := validateURL(a.JWT.JWKSURL); err != nil {
+			errs = append(errs, fmt.Errorf("JWT_JWKS_URL: %w", err))
+		}
+	}
+	if a.JWT.Audience == "" {
+		errs = append(errs, errors.New("JWT_AUDIENCE cannot be empty"))
+	}
+	if a.JWT.ClockSkew < 0 {
+		errs = append(errs, errors.New("JWT_CLOCK_SKEW cannot be negative"))
+	}
+	if a.JWT.JWKSRefreshInterval <= 0 {
+		errs = append(errs, errors.New("JWT_JWKS_REFRESH_INTERVAL must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the CORS settings
+func (c CORS) Validate() error {
+	var errs []error
+	if c.AllowCredentials && slices.Contains(c.AllowedOrigins, "*") {
+		// Reflecting every origin with credentials would let any site act as the user
+		errs = append(errs, errors.New("CORS_ALLOW_CREDENTIALS cannot be used while CORS_ALLOWED_ORIGINS allows any origin (*)"))
+	}
+	if c.MaxAge < 0 {
+		errs = append(errs, errors.New("CORS_MAX_AGE cannot be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the rate limits
+// A limit is either off or allows a positive number of requests per positive period;
+// anything in between, such as 0/1m, would silently turn the limit off
+func (r RateLimit) Validate() error {
+	var errs []error
+	check := func(name string, limit ratelimit.Limit) {
+		if limit == (ratelimit.Limit{}) {
+			return
+		}
+		if limit.Requests <= 0 || limit.Period <= 0 {
+			errs = append(errs, fmt.Errorf("%s=%d/%s must allow at least 1 request per positive period (use off to disable it)", name, limit.Requests, limit.Period))
+		}
+	}
+
+	check(rateLimitPrefix+"DEFAULT", r.Default)
+	groups := make([]string, 0, len(r.Groups))
+	for group := range r.Groups {
+		groups = append(groups, group)
+	}
+	slices.Sort(groups)
+	for _, group := range groups {
+		check(rateLimitPrefix+strings.ToUpper(group), r.Groups[group])
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the account deletion settings
+func (a AccountDeletion) Validate() error {
+	if a.GraceDays < 0 {
+		return fmt.Errorf("ACCOUNT_DELETION_GRACE_DAYS=%d cannot be negative", a.GraceDays)
+	}
+	return nil
+}
+
+// Validate checks the OpenAPI validation settings
+func (o OpenAPI) Validate() error {
+	var errs []error
+	if !slices.Contains([]string{OpenAPIModeOff, OpenAPIModeLog, OpenAPIModeStrict}, o.Mode) {
+		errs = append(errs, fmt.Errorf("OPENAPI_VALIDATION=%q must be %s, %s or %s", o.Mode, OpenAPIModeOff, OpenAPIModeLog, OpenAPIModeStrict))
+	}
+	if o.Mode != OpenAPIModeOff && o.SpecPath == "" {
+		errs = append(errs, errors.New("OPENAPI_SPEC_PATH is required unless OPENAPI_VALIDATION=off"))
+	}
+	return errors.Join(errs...)
+}
+
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q must be an http or https URL", value)
+	}
+	return nil
+}
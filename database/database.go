@@ -1,70 +1,92 @@
 package database
 
 import (
+	"fmt"
 	"log"
-	"os"
-	"time"
+	"time-tracker/config"
 	"time-tracker/models"
 
-	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-var DB *gorm.DB
-
-func Connect() {
-	// Load environment variables
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Warning: .env file not found, using system environment variables")
-	}
-
-	// Get database URL from environment
-	databaseURL := os.Getenv("DATABASE_URL")
-	if databaseURL == "" {
-		log.Fatal("DATABASE_URL environment variable is required")
-	}
-
+// Connect opens the PostgreSQL connection pool
+func Connect(cfg config.Database) *gorm.DB {
 	// Connect to PostgreSQL database
-	DB, err = gorm.Open(postgres.Open(databaseURL), &gorm.Config{
+	db, err := gorm.Open(postgres.Open(cfg.URL), &gorm.Config{
 		DisableForeignKeyConstraintWhenMigrating: true,
 	})
 	if err != nil {
-		log.Printf("Database connection failed. URL: %s", databaseURL)
 		log.Fatal("Failed to connect to database:", err)
 	}
 
 	// Configure connection pool and timeouts
-	sqlDB, err := DB.DB()
+	sqlDB, err := db.DB()
 	if err != nil {
 		log.Fatal("Failed to get underlying sql.DB:", err)
 	}
 
 	// Set connection pool settings
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	log.Println("Database connected successfully")
+	return db
+}
+
+// Models are the tables created with AutoMigrate
+// Profiles only come from the versioned migrations and auth.users is managed by Supabase
+var Models = []interface{}{
+	&models.TimeEntry{}, &models.Project{}, &models.DailyUserTotal{}, &models.Achievement{}, &models.UserAchievement{},
+	&models.LevelTier{}, &models.Goal{}, &models.Friendship{}, &models.Workspace{}, &models.WorkspaceMember{},
+	&models.WorkspaceInvitation{}, &models.Timesheet{}, &models.TimesheetEvent{}, &models.PeriodLock{},
+	&models.AuditLog{}, &models.PersonalAccessToken{},
 }
 
-func Migrate() {
+// Seed adds the default achievements and, if none are configured yet, the default level tiers
+// Achievements that were customised are kept
+func Seed(db *gorm.DB) error {
+	defaults := append([]models.Achievement(nil), models.DefaultAchievements...)
+	if err := db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).Create(&defaults).Error; err != nil {
+		return fmt.Errorf("seed achievements: %w", err)
+	}
+
+	var tierCount int64
+	if err := db.Model(&models.LevelTier{}).Count(&tierCount).Error; err != nil {
+		return fmt.Errorf("count level tiers: %w", err)
+	}
+	if tierCount == 0 {
+		tiers := append([]models.LevelTier(nil), models.DefaultLevelTiers...)
+		if err := db.Create(&tiers).Error; err != nil {
+			return fmt.Errorf("seed level tiers: %w", err)
+		}
+	}
+	return nil
+}
+
+// Migrate creates the tables and seeds the default data
+func Migrate(db *gorm.DB) {
 	// Note: For development, you can use GORM AutoMigrate
 	// For production, use versioned migrations with: make migrate-up
 
 	// Enable UUID extension for PostgreSQL
-	err := DB.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"").Error
+	err := db.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"").Error
 	if err != nil {
 		log.Fatal("Failed to create UUID extension:", err)
 	}
 
 	// Option 1: Use GORM AutoMigrate (for development)
-	err = DB.AutoMigrate(&models.TimeEntry{}, &models.Project{})
+	err = db.AutoMigrate(Models...)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	if err := Seed(db); err != nil {
+		log.Fatal("Failed to seed database:", err)
+	}
+
 	// Option 2: Use versioned migrations (recommended for production)
 	// Uncomment the lines below and comment out AutoMigrate above
 	// err = RunMigrations("up")
@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -12,11 +11,9 @@ import (
 )
 
 // RunMigrations executes database migrations
-func RunMigrations(direction string) error {
-	// Get database URL from environment
-	databaseURL := os.Getenv("DATABASE_URL")
+func RunMigrations(databaseURL, direction string) error {
 	if databaseURL == "" {
-		return fmt.Errorf("DATABASE_URL environment variable is required")
+		return fmt.Errorf("DATABASE_URL is required")
 	}
 
 	// Open database connection for migrations
@@ -67,10 +64,9 @@ func RunMigrations(direction string) error {
 }
 
 // MigrateToVersion migrates to a specific version
-func MigrateToVersion(version uint) error {
-	databaseURL := os.Getenv("DATABASE_URL")
+func MigrateToVersion(databaseURL string, version uint) error {
 	if databaseURL == "" {
-		return fmt.Errorf("DATABASE_URL environment variable is required")
+		return fmt.Errorf("DATABASE_URL is required")
 	}
 
 	db, err := sql.Open("postgres", databaseURL)
@@ -103,10 +99,9 @@ func MigrateToVersion(version uint) error {
 }
 
 // GetCurrentVersion returns the current migration version
-func GetCurrentVersion() (uint, bool, error) {
-	databaseURL := os.Getenv("DATABASE_URL")
+func GetCurrentVersion(databaseURL string) (uint, bool, error) {
 	if databaseURL == "" {
-		return 0, false, fmt.Errorf("DATABASE_URL environment variable is required")
+		return 0, false, fmt.Errorf("DATABASE_URL is required")
 	}
 
 	db, err := sql.Open("postgres", databaseURL)
@@ -0,0 +1,292 @@
+package goals
+
+import (
+	"time"
+	"time-tracker/models"
+	"time-tracker/stats"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// dayStatus is how a day counts towards a streak once daily goals are involved
+type dayStatus int
+
+const (
+	dayMissed    dayStatus = iota // Breaks the streak
+	dayCompleted                  // Extends the streak
+	dayRest                       // No daily goal applies; neither breaks nor extends the streak
+)
+
+// projectSeconds maps project IDs (uuid.Nil for no project) to seconds tracked on one day
+type projectSeconds map[uuid.UUID]int64
+
+// WeekdaysToMask converts a list of weekdays (0 = Sunday) into a weekday bit mask
+// An empty list means every day
+func WeekdaysToMask(weekdays []int) int {
+	if len(weekdays) == 0 {
+		return models.AllWeekdays
+	}
+	mask := 0
+	for _, weekday := range weekdays {
+		mask |= 1 << uint(weekday)
+	}
+	return mask
+}
+
+// MaskToWeekdays converts a weekday bit mask into a sorted list of weekdays
+func MaskToWeekdays(mask int) []int {
+	weekdays := []int{}
+	for weekday := 0; weekday < 7; weekday++ {
+		if mask&(1<<uint(weekday)) != 0 {
+			weekdays = append(weekdays, weekday)
+		}
+	}
+	return weekdays
+}
+
+// ToResponse converts a goal to its response format
+func ToResponse(goal models.Goal) models.GoalResponse {
+	response := models.GoalResponse{
+		ID:            goal.ID,
+		Period:        goal.Period,
+		TargetMinutes: goal.TargetMinutes,
+		Weekdays:      MaskToWeekdays(goal.Weekdays),
+		CreatedAt:     goal.CreatedAt,
+	}
+	if goal.Project != nil {
+		response.Project = &models.ProjectResponse{
+			ID:          goal.Project.ID,
+			Name:        goal.Project.Name,
+			Description: goal.Project.Description,
+			Color:       goal.Project.Color,
+			CreatedAt:   goal.Project.CreatedAt,
+		}
+	}
+	return response
+}
+
+// trackedSeconds returns the seconds that count towards the goal
+func trackedSeconds(goal models.Goal, seconds projectSeconds) int64 {
+	if goal.ProjectID != nil {
+		return seconds[*goal.ProjectID]
+	}
+	var total int64
+	for _, s := range seconds {
+		total += s
+	}
+	return total
+}
+
+// statusOn determines how a day counts given the user's daily goals
+// Days before the first goal was created fall back to "any time tracked counts"
+func statusOn(daily []models.Goal, day time.Time, seconds projectSeconds, loc *time.Location) dayStatus {
+	existed, applied := false, false
+	for _, goal := range daily {
+		if stats.LocalDate(goal.CreatedAt, loc).After(day) {
+			continue
+		}
+		existed = true
+		if !goal.AppliesOn(day.Weekday()) {
+			continue
+		}
+		applied = true
+		if trackedSeconds(goal, seconds) < int64(goal.TargetMinutes)*60 {
+			return dayMissed
+		}
+	}
+
+	switch {
+	case applied:
+		return dayCompleted
+	case existed:
+		return dayRest
+	case trackedSeconds(models.Goal{}, seconds) > 0:
+		return dayCompleted
+	default:
+		return dayMissed
+	}
+}
+
+// loadDailyGoals returns the daily goals of each user
+func loadDailyGoals(db *gorm.DB, userIDs []uuid.UUID) (map[uuid.UUID][]models.Goal, error) {
+	var goals []models.Goal
+	if err := db.Where("user_id IN ? AND period = ?", userIDs, models.GoalPeriodDaily).Find(&goals).Error; err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[uuid.UUID][]models.Goal)
+	for _, goal := range goals {
+		byUser[goal.UserID] = append(byUser[goal.UserID], goal)
+	}
+	return byUser, nil
+}
+
+// loadDaySeconds returns the seconds tracked per user, local date and project between two dates (inclusive)
+func loadDaySeconds(db *gorm.DB, userIDs []uuid.UUID, from, to time.Time) (map[uuid.UUID]map[time.Time]projectSeconds, error) {
+	var rows []models.DailyUserTotal
+	if err := db.Where("user_id IN ? AND local_date BETWEEN ? AND ?", userIDs, from.Format(stats.DateFormat), to.Format(stats.DateFormat)).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID]map[time.Time]projectSeconds)
+	for _, row := range rows {
+		day := time.Date(row.LocalDate.Year(), row.LocalDate.Month(), row.LocalDate.Day(), 0, 0, 0, 0, time.UTC)
+		if result[row.UserID] == nil {
+			result[row.UserID] = make(map[time.Time]projectSeconds)
+		}
+		if result[row.UserID][day] == nil {
+			result[row.UserID][day] = make(projectSeconds)
+		}
+		result[row.UserID][day][row.ProjectID] += row.Seconds
+	}
+	return result, nil
+}
+
+// Streaks computes the streaks of several users
+// For users with daily goals a day only counts once all goals applying to it are met,
+// and days where none applies are rest days; everyone else keeps the "any time tracked" rule
+func Streaks(db *gorm.DB, userIDs []uuid.UUID, locations map[uuid.UUID]*time.Location, now time.Time) (map[uuid.UUID]stats.Streaks, error) {
+	activeDays, err := stats.LoadActiveDays(db, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	dailyGoals, err := loadDailyGoals(db, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	withGoals := make([]uuid.UUID, 0, len(dailyGoals))
+	for userID := range dailyGoals {
+		withGoals = append(withGoals, userID)
+	}
+
+	var daySeconds map[uuid.UUID]map[time.Time]projectSeconds
+	if len(withGoals) > 0 {
+		daySeconds, err = loadDaySeconds(db, withGoals, time.Time{}, stats.LocalDate(now, time.UTC).AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(map[uuid.UUID]stats.Streaks, len(userIDs))
+	for _, userID := range userIDs {
+		loc := locations[userID]
+		if loc == nil {
+			loc = time.UTC
+		}
+
+		goals, ok := dailyGoals[userID]
+		if !ok {
+			result[userID] = stats.CalculateStreaks(activeDays[userID], loc, now)
+			continue
+		}
+
+		var completed []time.Time
+		for day, seconds := range daySeconds[userID] {
+			if statusOn(goals, day, seconds, loc) == dayCompleted {
+				completed = append(completed, day)
+			}
+		}
+
+		isRestDay := func(day time.Time) bool {
+			return statusOn(goals, day, nil, loc) == dayRest
+		}
+
+		streaks := stats.CalculateStreaksWithRestDays(completed, isRestDay, loc, now)
+		streaks.ActiveDays = len(activeDays[userID])
+		result[userID] = streaks
+	}
+	return result, nil
+}
+
+// Progress returns the user's progress on daily goals for today and on weekly goals for this week
+func Progress(db *gorm.DB, profile models.Profile, now time.Time) (models.GoalProgressResponse, error) {
+	loc := profile.Location()
+	today := stats.LocalDate(now, loc)
+	weekStart := stats.LocalDate(profile.StartOfWeek(now), loc)
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	response := models.GoalProgressResponse{
+		Date:      today.Format(stats.DateFormat),
+		WeekStart: weekStart.Format(stats.DateFormat),
+		Daily:     []models.GoalProgress{},
+		Weekly:    []models.GoalProgress{},
+	}
+
+	var goals []models.Goal
+	if err := db.Preload("Project").Where("user_id = ?", profile.ID).Order("created_at").Find(&goals).Error; err != nil {
+		return response, err
+	}
+
+	daySeconds, err := loadDaySeconds(db, []uuid.UUID{profile.ID}, weekStart, weekEnd)
+	if err != nil {
+		return response, err
+	}
+
+	weekSeconds := make(projectSeconds)
+	for _, seconds := range daySeconds[profile.ID] {
+		for projectID, s := range seconds {
+			weekSeconds[projectID] += s
+		}
+	}
+
+	for _, goal := range goals {
+		progress := models.GoalProgress{Goal: ToResponse(goal), Applicable: true}
+		var tracked int64
+
+		if goal.Period == models.GoalPeriodDaily {
+			progress.PeriodStart = response.Date
+			progress.PeriodEnd = response.Date
+			progress.Applicable = goal.AppliesOn(today.Weekday())
+			tracked = trackedSeconds(goal, daySeconds[profile.ID][today])
+		} else {
+			progress.PeriodStart = response.WeekStart
+			progress.PeriodEnd = weekEnd.Format(stats.DateFormat)
+			tracked = trackedSeconds(goal, weekSeconds)
+		}
+
+		progress.TrackedMinutes = int(tracked / 60)
+		progress.Completed = tracked >= int64(goal.TargetMinutes)*60
+		percent := float32(tracked) / float32(goal.TargetMinutes*60) * 100
+		if percent > 100 {
+			percent = 100
+		}
+		progress.Percent = float32(int(percent*10+0.5)) / 10
+
+		if goal.Period == models.GoalPeriodDaily {
+			response.Daily = append(response.Daily, progress)
+		} else {
+			response.Weekly = append(response.Weekly, progress)
+		}
+	}
+
+	return response, nil
+}
+
+// History returns the outcome of the user's daily goals for each day between from and to (inclusive)
+func History(db *gorm.DB, profile models.Profile, from, to time.Time) ([]models.GoalDay, error) {
+	dailyGoals, err := loadDailyGoals(db, []uuid.UUID{profile.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	daySeconds, err := loadDaySeconds(db, []uuid.UUID{profile.ID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := profile.Location()
+	history := []models.GoalDay{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		status := statusOn(dailyGoals[profile.ID], day, daySeconds[profile.ID][day], loc)
+		history = append(history, models.GoalDay{
+			Date:       day.Format(stats.DateFormat),
+			Applicable: status != dayRest,
+			Completed:  status == dayCompleted,
+		})
+	}
+	return history, nil
+}
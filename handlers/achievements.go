@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"time-tracker/achievements"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AchievementHandler serves the achievement endpoints
+type AchievementHandler struct {
+	db *gorm.DB
+}
+
+// NewAchievementHandler returns an achievement handler using the database
+func NewAchievementHandler(db *gorm.DB) *AchievementHandler {
+	return &AchievementHandler{db: db}
+}
+
+// List returns all achievements with the current user's unlock status
+func (h *AchievementHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	list, err := achievements.ForUser(h.db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch achievements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time-tracker/jobs"
+	"time-tracker/models"
+	"time-tracker/stats"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AdminHandler serves the admin user endpoints
+type AdminHandler struct {
+	db *gorm.DB
+}
+
+// NewAdminHandler returns an admin handler using the database
+func NewAdminHandler(db *gorm.DB) *AdminHandler {
+	return &AdminHandler{db: db}
+}
+
+// userID parses the :id path parameter and checks that the user has a profile
+func (h *AdminHandler) userID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return uuid.Nil, false
+	}
+
+	var count int64
+	if err := h.db.Model(&models.Profile{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return uuid.Nil, false
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// ListUsers returns every user with a profile, newest first
+// Supports q to search by name and page/limit pagination
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	page, limit := pagination(c, 10)
+
+	query := h.db.Model(&models.Profile{})
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		// LOWER/LIKE rather than ILIKE so the search also runs on SQLite in tests
+		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(q)+"%")
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count users"})
+		return
+	}
+
+	var profiles []models.Profile
+	if err := query.Order("created_at DESC").Limit(limit).Offset((page - 1) * limit).Find(&profiles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(profiles))
+	for _, profile := range profiles {
+		userIDs = append(userIDs, profile.ID)
+	}
+
+	var users []models.User
+	if err := h.db.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+	emails := make(map[uuid.UUID]string, len(users))
+	for _, user := range users {
+		emails[user.ID] = user.Email
+	}
+
+	data := make([]models.AdminUserResponse, 0, len(profiles))
+	for _, profile := range profiles {
+		data = append(data, models.AdminUserResponse{
+			ID:                  profile.ID,
+			Name:                profile.Name,
+			Email:               emails[profile.ID],
+			Timezone:            profile.Timezone,
+			Visibility:          profile.Visibility,
+			DeletionScheduledAt: profile.DeletionScheduledAt,
+			CreatedAt:           profile.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedAdminUsersResponse{
+		Data:       data,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	})
+}
+
+// ListUserTimeEntries returns any user's time entries, newest first, with page/limit pagination
+func (h *AdminHandler) ListUserTimeEntries(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	page, limit := pagination(c, 10)
+
+	query := h.db.Model(&models.TimeEntry{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count time entries"})
+		return
+	}
+
+	var timeEntries []models.TimeEntry
+	if err := query.Preload("Project").Order("created_at DESC").Limit(limit).Offset((page - 1) * limit).Find(&timeEntries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch time entries"})
+		return
+	}
+
+	data := make([]models.TimeEntryResponse, 0, len(timeEntries))
+	for _, entry := range timeEntries {
+		data = append(data, timeEntryResponse(entry))
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedTimeEntriesResponse{
+		Data:       data,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	})
+}
+
+// RecalculateUserStats rebuilds one user's daily totals from their time entries
+func (h *AdminHandler) RecalculateUserStats(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		return stats.RebuildUser(tx, userID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recalculate statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Statistics recalculated successfully"})
+}
+
+// RecalculateStats starts rebuilding every user's daily totals from their time entries
+// This can take a while, so it runs in the background and the request returns right away
+func (h *AdminHandler) RecalculateStats(c *gin.Context) {
+	if !jobs.StartStatsRebuild(h.db) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Statistics recalculation already running"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Statistics recalculation started"})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"time-tracker/audit"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditHandler serves the audit log endpoints
+type AuditHandler struct {
+	db *gorm.DB
+}
+
+// NewAuditHandler returns an audit log handler using the database
+func NewAuditHandler(db *gorm.DB) *AuditHandler {
+	return &AuditHandler{db: db}
+}
+
+// auditContext describes the caller and the request for audit log entries
+func auditContext(c *gin.Context) audit.Context {
+	ctx := audit.Context{
+		RequestID: c.GetString("request_id"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		if actorID, ok := userID.(uuid.UUID); ok {
+			ctx.ActorID = &actorID
+		}
+	}
+	return ctx
+}
+
+// List returns audit log entries, newest first
+// Supports filtering by actor_id, action, entity_type, entity_id, request_id and a from/to time range,
+// with page/limit pagination like GetTimeEntries
+func (h *AuditHandler) List(c *gin.Context) {
+	page, limit := pagination(c, 20)
+
+	query := h.db.Model(&models.AuditLog{})
+
+	for _, param := range []string{"actor_id", "entity_id"} {
+		if value := c.Query(param); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+				return
+			}
+			query = query.Where(param+" = ?", id)
+		}
+	}
+	for _, param := range []string{"action", "entity_type", "request_id"} {
+		if value := c.Query(param); value != "" {
+			query = query.Where(param+" = ?", value)
+		}
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from time format"})
+			return
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to time format"})
+			return
+		}
+		query = query.Where("created_at < ?", t)
+	}
+
+	// Get total count
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count audit log entries"})
+		return
+	}
+
+	entries := []models.AuditLog{}
+	if err := query.Order("created_at DESC, id").Limit(limit).Offset((page - 1) * limit).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedAuditLogResponse{
+		Data:       entries,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+	"time-tracker/middleware"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DevTokenHandler issues development tokens
+type DevTokenHandler struct {
+	auth *middleware.Authenticator
+}
+
+// NewDevTokenHandler returns a development token handler issuing tokens the authenticator accepts
+func NewDevTokenHandler(auth *middleware.Authenticator) *DevTokenHandler {
+	return &DevTokenHandler{auth: auth}
+}
+
+// Issue returns a token for any user, for local testing
+// Only routed with AUTH_MODE=insecure-dev, the only mode that accepts these tokens
+func (h *DevTokenHandler) Issue(c *gin.Context) {
+	var req models.DevTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := uuid.New()
+	if req.UserID != nil {
+		userID = *req.UserID
+	}
+	role := req.Role
+	if role == "" {
+		role = "authenticated"
+	}
+	ttl := time.Hour
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+
+	token, expiresAt, err := h.auth.NewDevToken(userID, req.Email, role, ttl)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.DevTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		UserID:      userID,
+		ExpiresAt:   expiresAt,
+	})
+}
@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FriendHandler serves the friend endpoints
+type FriendHandler struct {
+	db *gorm.DB
+}
+
+// NewFriendHandler returns a friend handler using the database
+func NewFriendHandler(db *gorm.DB) *FriendHandler {
+	return &FriendHandler{db: db}
+}
+
+// findFriendship returns the friendship between two users in either direction, or nil if there is none
+func findFriendship(db *gorm.DB, userID, otherID uuid.UUID) (*models.Friendship, error) {
+	var friendship models.Friendship
+	err := db.Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)",
+		userID, otherID, otherID, userID).First(&friendship).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &friendship, nil
+}
+
+// friendIDs returns the IDs of the user's accepted friends
+func friendIDs(db *gorm.DB, userID uuid.UUID) ([]uuid.UUID, error) {
+	var friendships []models.Friendship
+	if err := db.Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, models.FriendshipAccepted).
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(friendships))
+	for _, friendship := range friendships {
+		ids = append(ids, friendship.Other(userID))
+	}
+	return ids, nil
+}
+
+// friendResponses describes the other user of each friendship
+func friendResponses(db *gorm.DB, userID uuid.UUID, friendships []models.Friendship) ([]models.FriendResponse, error) {
+	otherIDs := make([]uuid.UUID, 0, len(friendships))
+	for _, friendship := range friendships {
+		otherIDs = append(otherIDs, friendship.Other(userID))
+	}
+
+	var profiles []models.Profile
+	if err := db.Where("id IN ?", otherIDs).Find(&profiles).Error; err != nil {
+		return nil, err
+	}
+	profilesByID := make(map[uuid.UUID]models.Profile, len(profiles))
+	for _, profile := range profiles {
+		profilesByID[profile.ID] = profile
+	}
+
+	responses := make([]models.FriendResponse, 0, len(friendships))
+	for _, friendship := range friendships {
+		otherID := friendship.Other(userID)
+		profile := profilesByID[otherID]
+		responses = append(responses, models.FriendResponse{
+			FriendshipID:      friendship.ID,
+			UserID:            otherID,
+			Name:              profile.Name,
+			ProfilePictureURL: profile.ProfilePictureURL,
+			Since:             friendship.UpdatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// List returns the current user's accepted friends
+func (h *FriendHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var friendships []models.Friendship
+	if err := h.db.Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, models.FriendshipAccepted).
+		Order("updated_at DESC").Find(&friendships).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friends"})
+		return
+	}
+
+	friends, err := friendResponses(h.db, userID, friendships)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friends"})
+		return
+	}
+
+	c.JSON(http.StatusOK, friends)
+}
+
+// SendRequest asks another user to become friends
+// If that user already asked the caller, their request is accepted instead
+func (h *FriendHandler) SendRequest(c *gin.Context) {
+	var req models.FriendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if req.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot send a friend request to yourself"})
+		return
+	}
+
+	var profile models.Profile
+	if err := h.db.Where("id = ?", req.UserID).First(&profile).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	existing, err := findFriendship(h.db, userID, req.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send friend request"})
+		return
+	}
+
+	var friendship models.Friendship
+	status := http.StatusCreated
+
+	switch {
+	case existing == nil:
+		friendship = models.Friendship{
+			RequesterID: userID,
+			AddresseeID: req.UserID,
+			Status:      models.FriendshipPending,
+		}
+		if err := h.db.Create(&friendship).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send friend request"})
+			return
+		}
+	case existing.Status == models.FriendshipBlocked:
+		// Do not reveal who blocked whom
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot send a friend request to this user"})
+		return
+	case existing.Status == models.FriendshipAccepted:
+		c.JSON(http.StatusConflict, gin.H{"error": "Already friends"})
+		return
+	case existing.RequesterID == userID:
+		c.JSON(http.StatusConflict, gin.H{"error": "Friend request already sent"})
+		return
+	default:
+		// The other user already asked, so both want to be friends
+		friendship = *existing
+		friendship.Status = models.FriendshipAccepted
+		if err := h.db.Save(&friendship).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept friend request"})
+			return
+		}
+		status = http.StatusOK
+	}
+
+	responses, err := friendResponses(h.db, userID, []models.Friendship{friendship})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friend request"})
+		return
+	}
+
+	c.JSON(status, responses[0])
+}
+
+// ListRequests returns the pending friend requests sent to and by the current user
+func (h *FriendHandler) ListRequests(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var incoming, outgoing []models.Friendship
+	if err := h.db.Where("addressee_id = ? AND status = ?", userID, models.FriendshipPending).
+		Order("created_at DESC").Find(&incoming).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friend requests"})
+		return
+	}
+	if err := h.db.Where("requester_id = ? AND status = ?", userID, models.FriendshipPending).
+		Order("created_at DESC").Find(&outgoing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friend requests"})
+		return
+	}
+
+	incomingResponses, err := friendResponses(h.db, userID, incoming)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friend requests"})
+		return
+	}
+	outgoingResponses, err := friendResponses(h.db, userID, outgoing)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friend requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.FriendRequestsResponse{
+		Incoming: incomingResponses,
+		Outgoing: outgoingResponses,
+	})
+}
+
+// AcceptRequest accepts a pending friend request sent to the current user
+func (h *FriendHandler) AcceptRequest(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var friendship models.Friendship
+	if err := h.db.Where("id = ? AND addressee_id = ? AND status = ?", id, userID, models.FriendshipPending).
+		First(&friendship).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Friend request not found"})
+		return
+	}
+
+	friendship.Status = models.FriendshipAccepted
+	if err := h.db.Save(&friendship).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept friend request"})
+		return
+	}
+
+	responses, err := friendResponses(h.db, userID, []models.Friendship{friendship})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friend"})
+		return
+	}
+
+	c.JSON(http.StatusOK, responses[0])
+}
+
+// DeleteRequest declines a request sent to the current user or cancels one they sent
+func (h *FriendHandler) DeleteRequest(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	result := h.db.Where("id = ? AND (requester_id = ? OR addressee_id = ?) AND status = ?", id, userID, userID, models.FriendshipPending).
+		Delete(&models.Friendship{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete friend request"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Friend request not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend request deleted successfully"})
+}
+
+// Remove ends a friendship with another user
+func (h *FriendHandler) Remove(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	otherID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	result := h.db.Where("((requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)) AND status = ?",
+		userID, otherID, otherID, userID, models.FriendshipAccepted).Delete(&models.Friendship{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove friend"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Friend not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend removed successfully"})
+}
+
+// ListBlocked returns the users the current user has blocked
+func (h *FriendHandler) ListBlocked(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var blocks []models.Friendship
+	if err := h.db.Where("requester_id = ? AND status = ?", userID, models.FriendshipBlocked).
+		Order("updated_at DESC").Find(&blocks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blocked users"})
+		return
+	}
+
+	blocked, err := friendResponses(h.db, userID, blocks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blocked users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, blocked)
+}
+
+// Block blocks another user, replacing any friendship or pending request with them
+func (h *FriendHandler) Block(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	otherID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if otherID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot block yourself"})
+		return
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var blocked int64
+		if err := tx.Model(&models.Friendship{}).
+			Where("requester_id = ? AND addressee_id = ? AND status = ?", userID, otherID, models.FriendshipBlocked).
+			Count(&blocked).Error; err != nil {
+			return err
+		}
+		if blocked > 0 {
+			return nil
+		}
+
+		// Each user's block stands on its own, so a block by the other user stays next to this one
+		if err := tx.Where("((requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)) AND status <> ?",
+			userID, otherID, otherID, userID, models.FriendshipBlocked).
+			Delete(&models.Friendship{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.Friendship{
+			RequesterID: userID,
+			AddresseeID: otherID,
+			Status:      models.FriendshipBlocked,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User blocked successfully"})
+}
+
+// Unblock lifts a block the current user placed on another user
+func (h *FriendHandler) Unblock(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	otherID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	result := h.db.Where("requester_id = ? AND addressee_id = ? AND status = ?", userID, otherID, models.FriendshipBlocked).
+		Delete(&models.Friendship{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unblock user"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blocked user not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unblocked successfully"})
+}
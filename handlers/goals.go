@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"time-tracker/goals"
+	"time-tracker/models"
+	"time-tracker/services"
+	"time-tracker/stats"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GoalHandler serves the goal endpoints
+type GoalHandler struct {
+	db       *gorm.DB
+	projects *services.ProjectService // Decides which projects goals can be scoped to
+}
+
+// NewGoalHandler returns a goal handler using the database and project service
+func NewGoalHandler(db *gorm.DB, projects *services.ProjectService) *GoalHandler {
+	return &GoalHandler{db: db, projects: projects}
+}
+
+// validateTrackableProject checks that the user may track time on the project:
+// one of their personal projects or a workspace project where they are at least a member
+func (h *GoalHandler) validateTrackableProject(c *gin.Context, projectID *uuid.UUID, userID uuid.UUID) bool {
+	err := h.projects.CheckTrackable(requestContext(c), userID, projectID)
+	if err != nil {
+		respondError(c, err, "Failed to fetch workspace membership")
+		return false
+	}
+	return true
+}
+
+func (h *GoalHandler) Create(c *gin.Context) {
+	var req models.GoalCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if !h.validateTrackableProject(c, req.ProjectID, userID) {
+		return
+	}
+
+	goal := models.Goal{
+		UserID:        userID,
+		ProjectID:     req.ProjectID,
+		Period:        req.Period,
+		TargetMinutes: req.TargetMinutes,
+		Weekdays:      goals.WeekdaysToMask(req.Weekdays),
+	}
+
+	if err := h.db.Create(&goal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create goal"})
+		return
+	}
+
+	if err := h.db.Preload("Project").First(&goal, "id = ?", goal.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch created goal"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, goals.ToResponse(goal))
+}
+
+func (h *GoalHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var list []models.Goal
+	if err := h.db.Preload("Project").Where("user_id = ?", userID).Order("created_at").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch goals"})
+		return
+	}
+
+	data := make([]models.GoalResponse, 0, len(list))
+	for _, goal := range list {
+		data = append(data, goals.ToResponse(goal))
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+func (h *GoalHandler) Update(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req models.GoalUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var goal models.Goal
+	if err := h.db.Where("id = ? AND user_id = ?", id, userID).First(&goal).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		return
+	}
+
+	if !h.validateTrackableProject(c, req.ProjectID, userID) {
+		return
+	}
+
+	// Update fields
+	if req.TargetMinutes != nil {
+		goal.TargetMinutes = *req.TargetMinutes
+	}
+	if req.Weekdays != nil {
+		goal.Weekdays = goals.WeekdaysToMask(req.Weekdays)
+	}
+	if req.ProjectID != nil {
+		goal.ProjectID = req.ProjectID
+	}
+
+	if err := h.db.Save(&goal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update goal"})
+		return
+	}
+
+	if err := h.db.Preload("Project").First(&goal, "id = ?", goal.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated goal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, goals.ToResponse(goal))
+}
+
+func (h *GoalHandler) Delete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	result := h.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Goal{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete goal"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted successfully"})
+}
+
+// Progress returns progress on daily goals for today and weekly goals for this week
+func (h *GoalHandler) Progress(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Days and weeks follow the user's timezone and week start day
+	var profile models.Profile
+	if err := h.db.Where("id = ?", userID).First(&profile).Error; err != nil {
+		profile = models.Profile{ID: userID, WeekStartDay: 1}
+	}
+
+	progress, err := goals.Progress(h.db, profile, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch goal progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// History returns whether the daily goals were met on each of the last N days
+func (h *GoalHandler) History(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	days := 30 // default number of days
+	if daysStr := c.Query("days"); daysStr != "" {
+		if d, err := strconv.Atoi(daysStr); err == nil && d > 0 && d <= 366 {
+			days = d
+		}
+	}
+
+	var profile models.Profile
+	if err := h.db.Where("id = ?", userID).First(&profile).Error; err != nil {
+		profile = models.Profile{ID: userID}
+	}
+
+	to := stats.LocalDate(time.Now(), profile.Location())
+	from := to.AddDate(0, 0, -(days - 1))
+
+	history, err := goals.History(h.db, profile, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch goal history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
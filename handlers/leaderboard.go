@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"time-tracker/goals"
+	"time-tracker/levels"
+	"time-tracker/models"
+	"time-tracker/stats"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LeaderboardHandler serves the leaderboard endpoints
+type LeaderboardHandler struct {
+	db *gorm.DB
+}
+
+// NewLeaderboardHandler returns a leaderboard handler using the database
+func NewLeaderboardHandler(db *gorm.DB) *LeaderboardHandler {
+	return &LeaderboardHandler{db: db}
+}
+
+// canSeeIdentity reports whether the viewer may see the name and picture of the profile owner
+func canSeeIdentity(viewerID uuid.UUID, profile models.Profile, friends map[uuid.UUID]bool) bool {
+	return profile.ID == viewerID || profile.Visibility != models.VisibilityFriends || friends[profile.ID]
+}
+
+// anonymize strips identifying details from a leaderboard entry
+func anonymize(entry *models.LeaderboardEntry) {
+	entry.UserID = uuid.Nil
+	entry.Name = "Anonymous"
+	entry.ProfilePictureURL = nil
+}
+
+// leaderboardPeriodStart returns the start of the period in the user's timezone
+// The zero time is returned for the all-time period
+func leaderboardPeriodStart(period string, profile models.Profile, now time.Time) (time.Time, bool) {
+	local := now.In(profile.Location())
+	switch period {
+	case models.LeaderboardPeriodWeek:
+		return profile.StartOfWeek(now), true
+	case models.LeaderboardPeriodMonth:
+		return time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, local.Location()), true
+	case models.LeaderboardPeriodYear:
+		return time.Date(local.Year(), time.January, 1, 0, 0, 0, 0, local.Location()), true
+	case "", models.LeaderboardPeriodAll:
+		return time.Time{}, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// Get returns users ranked by time tracked in the requested period
+// Supports period=week|month|year|all, scope=global|friends, limit/offset pagination and
+// around_me=N to return the N users ranked directly above and below the caller
+func (h *LeaderboardHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Periods follow the caller's timezone and week start day
+	var currentProfile models.Profile
+	h.db.Where("id = ?", userID).First(&currentProfile)
+
+	periodStart, ok := leaderboardPeriodStart(c.Query("period"), currentProfile, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period (use week, month, year or all)"})
+		return
+	}
+	since := periodStart.Format(stats.DateFormat)
+
+	friends, err := friendIDs(h.db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
+		return
+	}
+	isFriend := make(map[uuid.UUID]bool, len(friends))
+	for _, friendID := range friends {
+		isFriend[friendID] = true
+	}
+
+	// The friends scope ranks only the caller and their friends
+	var members []uuid.UUID
+	switch c.Query("scope") {
+	case "", models.LeaderboardScopeGlobal:
+	case models.LeaderboardScopeFriends:
+		members = append(friends, userID)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scope (use global or friends)"})
+		return
+	}
+	query, args := stats.RankedUsersQuery(since, members)
+
+	limit, offset := offsetPagination(c, 5)
+
+	var total int64
+	if err := h.db.Raw(`SELECT COUNT(*) FROM (`+query+`) ranked`, args...).Scan(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
+		return
+	}
+
+	// Positions are 1-based and inclusive
+	from, to := offset+1, offset+limit
+
+	if aroundStr := c.Query("around_me"); aroundStr != "" {
+		around, err := strconv.Atoi(aroundStr)
+		if err != nil || around < 0 || around > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "around_me must be between 0 and 50"})
+			return
+		}
+
+		var ranked []stats.RankedUser
+		if err := h.db.Raw(`SELECT * FROM (`+query+`) ranked WHERE user_id = ?`, append(args, userID)...).Scan(&ranked).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
+			return
+		}
+
+		// Users without tracked time sit below everyone else
+		position := int(total) + 1
+		if len(ranked) > 0 {
+			position = ranked[0].Position
+		}
+		from, to = position-around, position+around
+	}
+
+	var results []stats.RankedUser
+	if err := h.db.Raw(`
+		SELECT * FROM (`+query+`) ranked
+		WHERE position BETWEEN ? AND ?
+		ORDER BY position
+	`, append(args, from, to)...).Scan(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
+		return
+	}
+
+	// Load everything the entries need in a fixed number of queries
+	userIDs := make([]uuid.UUID, 0, len(results))
+	for _, result := range results {
+		userIDs = append(userIDs, result.UserID)
+	}
+
+	var users []models.User
+	if err := h.db.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
+		return
+	}
+	usersByID := make(map[uuid.UUID]bool, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = true
+	}
+
+	var profiles []models.Profile
+	if err := h.db.Where("id IN ?", userIDs).Find(&profiles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
+		return
+	}
+	profilesByID := make(map[uuid.UUID]models.Profile, len(profiles))
+	for _, profile := range profiles {
+		profilesByID[profile.ID] = profile
+	}
+
+	// Levels always reflect all-time hours, whatever the period
+	var allTimeTotals []struct {
+		UserID  uuid.UUID
+		Seconds int64
+	}
+	if err := h.db.Model(&models.DailyUserTotal{}).
+		Select("user_id, SUM(seconds) AS seconds").
+		Where("user_id IN ?", userIDs).
+		Group("user_id").
+		Scan(&allTimeTotals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
+		return
+	}
+	allTimeSeconds := make(map[uuid.UUID]int64, len(allTimeTotals))
+	for _, total := range allTimeTotals {
+		allTimeSeconds[total.UserID] = total.Seconds
+	}
+
+	locations := make(map[uuid.UUID]*time.Location, len(profiles))
+	for _, profile := range profiles {
+		locations[profile.ID] = profile.Location()
+	}
+	userStreaks, err := goals.Streaks(h.db, userIDs, locations, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
+		return
+	}
+
+	leaderboard := make([]models.LeaderboardEntry, 0, len(results))
+
+	for _, result := range results {
+		if !usersByID[result.UserID] {
+			continue
+		}
+		profile := profilesByID[result.UserID]
+
+		streaks := userStreaks[result.UserID]
+
+		level := levels.ForHours(h.db, float32(allTimeSeconds[result.UserID])/3600)
+		totalHours := float32(int(result.TotalHours*10+0.5)) / 10
+
+		entry := models.LeaderboardEntry{
+			UserID:            result.UserID,
+			Name:              profile.Name,
+			ProfilePictureURL: profile.ProfilePictureURL,
+			TotalHours:        totalHours,
+			Level:             level.Level,
+			LevelColor:        level.LevelColor,
+			LevelIcon:         level.LevelIcon,
+			Rank:              result.Rank,
+			CurrentStreak:     streaks.CurrentStreak,
+			LongestStreak:     streaks.LongestStreak,
+			IsCurrentUser:     result.UserID == userID,
+		}
+
+		// Friends-only users are still ranked but shown anonymously to non-friends
+		if !canSeeIdentity(userID, profile, isFriend) {
+			anonymize(&entry)
+		}
+
+		leaderboard = append(leaderboard, entry)
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, leaderboard)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"time-tracker/levels"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LevelTierHandler serves the level tier endpoints
+type LevelTierHandler struct {
+	db *gorm.DB
+}
+
+// NewLevelTierHandler returns a level tier handler using the database
+func NewLevelTierHandler(db *gorm.DB) *LevelTierHandler {
+	return &LevelTierHandler{db: db}
+}
+
+// List returns all level tiers ordered by threshold
+func (h *LevelTierHandler) List(c *gin.Context) {
+	var tiers []models.LevelTier
+	if err := h.db.Order("min_hours").Find(&tiers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch level tiers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tiers)
+}
+
+// Create adds a new level tier
+func (h *LevelTierHandler) Create(c *gin.Context) {
+	var req models.LevelTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Thresholds must be unique so levels are unambiguous
+	var count int64
+	if err := h.db.Model(&models.LevelTier{}).Where("min_hours = ?", *req.MinHours).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create level tier"})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A level tier with this threshold already exists"})
+		return
+	}
+
+	tier := models.LevelTier{
+		Name:     req.Name,
+		MinHours: *req.MinHours,
+		Color:    req.Color,
+		Icon:     req.Icon,
+	}
+
+	if err := h.db.Create(&tier).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create level tier"})
+		return
+	}
+	levels.Invalidate()
+
+	c.JSON(http.StatusCreated, tier)
+}
+
+// Update replaces a level tier's threshold, name, color and icon
+func (h *LevelTierHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req models.LevelTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var tier models.LevelTier
+	if err := h.db.Where("id = ?", id).First(&tier).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Level tier not found"})
+		return
+	}
+
+	var count int64
+	if err := h.db.Model(&models.LevelTier{}).Where("min_hours = ? AND id <> ?", *req.MinHours, id).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update level tier"})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A level tier with this threshold already exists"})
+		return
+	}
+
+	tier.Name = req.Name
+	tier.MinHours = *req.MinHours
+	tier.Color = req.Color
+	tier.Icon = req.Icon
+
+	if err := h.db.Save(&tier).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update level tier"})
+		return
+	}
+	levels.Invalidate()
+
+	c.JSON(http.StatusOK, tier)
+}
+
+// Delete removes a level tier
+func (h *LevelTierHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	result := h.db.Where("id = ?", id).Delete(&models.LevelTier{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete level tier"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Level tier not found"})
+		return
+	}
+	levels.Invalidate()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Level tier deleted successfully"})
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"time-tracker/models"
+	"time-tracker/stats"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PeriodLockHandler serves the period lock endpoints
+type PeriodLockHandler struct {
+	db *gorm.DB
+}
+
+// NewPeriodLockHandler returns a period lock handler using the database
+func NewPeriodLockHandler(db *gorm.DB) *PeriodLockHandler {
+	return &PeriodLockHandler{db: db}
+}
+
+func periodLockResponse(lock models.PeriodLock) models.PeriodLockResponse {
+	return models.PeriodLockResponse{
+		ID:           lock.ID,
+		UserID:       lock.UserID,
+		LockedBefore: stats.LocalDate(lock.LockedBefore, time.UTC).Format(stats.DateFormat),
+		CreatedBy:    lock.CreatedBy,
+		CreatedAt:    lock.CreatedAt,
+	}
+}
+
+// List returns period locks, latest date first
+// Supports user_id to only return the locks that apply to one user, including global ones
+func (h *PeriodLockHandler) List(c *gin.Context) {
+	query := h.db.Order("locked_before DESC, created_at DESC")
+	if userIDParam := c.Query("user_id"); userIDParam != "" {
+		userID, err := uuid.Parse(userIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		query = query.Where("user_id IS NULL OR user_id = ?", userID)
+	}
+
+	var locks []models.PeriodLock
+	if err := query.Find(&locks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch period locks"})
+		return
+	}
+
+	data := make([]models.PeriodLockResponse, 0, len(locks))
+	for _, lock := range locks {
+		data = append(data, periodLockResponse(lock))
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+// Create locks every day before a date, for one user or for everyone
+func (h *PeriodLockHandler) Create(c *gin.Context) {
+	var req models.PeriodLockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	lockedBefore, err := time.Parse(stats.DateFormat, req.LockedBefore)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid locked_before format (use YYYY-MM-DD)"})
+		return
+	}
+
+	lock := models.PeriodLock{
+		UserID:       req.UserID,
+		LockedBefore: lockedBefore,
+		CreatedBy:    adminID,
+	}
+
+	if err := h.db.Create(&lock).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create period lock"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, periodLockResponse(lock))
+}
+
+// Delete removes a period lock, reopening its days unless another lock covers them
+func (h *PeriodLockHandler) Delete(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	result := h.db.Where("id = ?", id).Delete(&models.PeriodLock{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete period lock"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Period lock not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Period lock deleted successfully"})
+}
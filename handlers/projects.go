@@ -1,175 +1,115 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
-	"strconv"
-	"time-tracker/database"
 	"time-tracker/models"
+	"time-tracker/repository"
+	"time-tracker/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-func CreateProject(c *gin.Context) {
+// ProjectHandler serves the project endpoints
+type ProjectHandler struct {
+	projects *services.ProjectService
+}
+
+// NewProjectHandler returns a project handler using the service
+func NewProjectHandler(projects *services.ProjectService) *ProjectHandler {
+	return &ProjectHandler{projects: projects}
+}
+
+// Create adds a personal project, or a shared one in a workspace the user administers
+func (h *ProjectHandler) Create(c *gin.Context) {
 	var req models.ProjectCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	// Validate project name - don't allow "General"
-	if req.Name == "General" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Project name 'General' is reserved"})
-		return
-	}
-
-	// Set default color if not provided
-	color := req.Color
-	if color == "" {
-		color = "#3B82F6" // Default blue color
-	}
-
-	project := models.Project{
-		UserID:      userID.(uuid.UUID),
-		Name:        req.Name,
-		Description: req.Description,
-		Color:       color,
-	}
-
-	if err := database.DB.Create(&project).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project"})
+	project, err := h.projects.Create(requestContext(c), userID, req)
+	if err != nil {
+		respondError(c, err, "Failed to create project")
 		return
 	}
 
-	c.JSON(http.StatusCreated, models.ProjectResponse{
-		ID:          project.ID,
-		Name:        project.Name,
-		Description: project.Description,
-		Color:       project.Color,
-		CreatedAt:   project.CreatedAt,
-	})
+	c.JSON(http.StatusCreated, projectResponse(project))
 }
 
-func GetProjects(c *gin.Context) {
-	// Get user ID from context (set by auth middleware)
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+// List returns the user's personal projects and the projects shared with them, newest first
+// Supports workspace_id to list one workspace's projects and page/limit pagination
+func (h *ProjectHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
-	userID := userIDInterface.(uuid.UUID)
 
-	// Parse pagination parameters
-	page := 1
-	limit := 10 // default limit
+	page, limit := pagination(c, 10)
 
-	if pageStr := c.Query("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
+	var workspaceID *uuid.UUID
+	if workspaceIDStr := c.Query("workspace_id"); workspaceIDStr != "" {
+		id, err := uuid.Parse(workspaceIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+			return
 		}
+		workspaceID = &id
 	}
 
-	log.Printf("GetProjects pagination: page=%d, limit=%d, offset=%d", page, limit, (page-1)*limit)
-
-	// Calculate offset
-	offset := (page - 1) * limit
-
-	// Get total count
-	var total int64
-	if err := database.DB.Model(&models.Project{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count projects"})
-		return
-	}
-
-	// Get paginated time entries
-	var projects []models.Project
-	if err := database.DB.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&projects).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch projects"})
+	projects, total, err := h.projects.List(requestContext(c), userID, workspaceID, repository.Page{Number: page, Limit: limit})
+	if err != nil {
+		respondError(c, err, "Failed to fetch projects")
 		return
 	}
 
-	// Convert to response format
 	var data []models.ProjectResponse
-	for _, entry := range projects {
-		data = append(data, models.ProjectResponse{
-			ID:          entry.ID,
-			Name:        entry.Name,
-			Description: entry.Description,
-			Color:       entry.Color,
-			CreatedAt:   entry.CreatedAt,
-		})
+	for _, project := range projects {
+		data = append(data, projectResponse(project))
 	}
 
-	// Calculate total pages
-	totalPages := int((total + int64(limit) - 1) / int64(limit))
-
-	response := models.PaginatedProjectResponse{
+	c.JSON(http.StatusOK, models.PaginatedProjectResponse{
 		Data:       data,
 		Page:       page,
 		Limit:      limit,
 		Total:      total,
-		TotalPages: totalPages,
-	}
-
-	c.JSON(http.StatusOK, response)
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	})
 }
 
-func GetProject(c *gin.Context) {
-	// Get user ID from context (set by auth middleware)
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+// Get returns one project the user can access
+func (h *ProjectHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
-	userID := userIDInterface.(uuid.UUID)
-
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	id, ok := pathID(c)
+	if !ok {
 		return
 	}
 
-	var project models.Project
-	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&project).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	project, err := h.projects.Get(requestContext(c), userID, id)
+	if err != nil {
+		respondError(c, err, "Failed to fetch project")
 		return
 	}
 
-	c.JSON(http.StatusOK, models.ProjectResponse{
-		ID:          project.ID,
-		Name:        project.Name,
-		Description: project.Description,
-		Color:       project.Color,
-		CreatedAt:   project.CreatedAt,
-	})
+	c.JSON(http.StatusOK, projectResponse(project))
 }
 
-func UpdateProject(c *gin.Context) {
-	// Get user ID from context (set by auth middleware)
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+// Update changes a project the user manages
+func (h *ProjectHandler) Update(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
-	userID := userIDInterface.(uuid.UUID)
-
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	id, ok := pathID(c)
+	if !ok {
 		return
 	}
 
@@ -179,92 +119,28 @@ func UpdateProject(c *gin.Context) {
 		return
 	}
 
-	var project models.Project
-	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&project).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-		return
-	}
-
-	// Update fields
-	if req.Name != "" {
-		// Validate project name - don't allow "General"
-		if req.Name == "General" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Project name 'General' is reserved"})
-			return
-		}
-		project.Name = req.Name
-	}
-	if req.Description != "" {
-		project.Description = req.Description
-	}
-	if req.Color != "" {
-		project.Color = req.Color
-	}
-
-	if err := database.DB.Save(&project).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
-		return
-	}
-
-	c.JSON(http.StatusOK, models.ProjectResponse{
-		ID:          project.ID,
-		Name:        project.Name,
-		Description: project.Description,
-		Color:       project.Color,
-		CreatedAt:   project.CreatedAt,
-	})
-}
-
-func DeleteProject(c *gin.Context) {
-	// Get user ID from context (set by auth middleware)
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
-	userID := userIDInterface.(uuid.UUID)
-
-	id, err := uuid.Parse(c.Param("id"))
+	project, err := h.projects.Update(requestContext(c), userID, id, req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		respondError(c, err, "Failed to update project")
 		return
 	}
 
-	// Start a database transaction
-	tx := database.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// First, verify the project exists and belongs to the user
-	var project models.Project
-	if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&project).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-		return
-	}
+	c.JSON(http.StatusOK, projectResponse(project))
+}
 
-	// Set project_id to null for all time entries associated with this project
-	if err := tx.Model(&models.TimeEntry{}).
-		Where("project_id = ? AND user_id = ?", id, userID).
-		Update("project_id", nil).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update time entries"})
+// Delete removes a project the user manages; its time entries are kept without a project
+func (h *ProjectHandler) Delete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
-
-	// Now delete the project
-	if err := tx.Delete(&project).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project"})
+	id, ok := pathID(c)
+	if !ok {
 		return
 	}
 
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+	if err := h.projects.Delete(requestContext(c), userID, id); err != nil {
+		respondError(c, err, "Failed to delete project")
 		return
 	}
 
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time-tracker/audit"
+	"time-tracker/models"
+	"time-tracker/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// statusByKind maps service error kinds to HTTP statuses
+var statusByKind = map[services.Kind]int{
+	services.KindInvalid:   http.StatusBadRequest,
+	services.KindForbidden: http.StatusForbidden,
+	services.KindNotFound:  http.StatusNotFound,
+	services.KindConflict:  http.StatusConflict,
+	services.KindLocked:    http.StatusLocked,
+	services.KindInternal:  http.StatusInternalServerError,
+}
+
+// requestContext carries the request's audit context to the service layer
+func requestContext(c *gin.Context) context.Context {
+	return audit.WithContext(c.Request.Context(), auditContext(c))
+}
+
+// currentUserID returns the authenticated user set by the auth middleware
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return uuid.Nil, false
+	}
+
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return uuid.Nil, false
+	}
+	return uid, true
+}
+
+// pathID parses the :id path parameter
+func pathID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// pagination parses page/limit query parameters: page defaults to 1, limit to defaultLimit and at most 100
+func pagination(c *gin.Context, defaultLimit int) (int, int) {
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	return page, queryLimit(c, defaultLimit)
+}
+
+// offsetPagination parses limit/offset query parameters: limit defaults to defaultLimit and at most 100, offset to 0
+func offsetPagination(c *gin.Context, defaultLimit int) (int, int) {
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+	return queryLimit(c, defaultLimit), offset
+}
+
+// queryLimit parses the limit query parameter, ignoring values outside 1-100
+func queryLimit(c *gin.Context, defaultLimit int) int {
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			return l
+		}
+	}
+	return defaultLimit
+}
+
+// respondError writes a service error with its status and message
+// Other errors are internal; their details stay in the logs and the client gets fallback
+func respondError(c *gin.Context, err error, fallback string) {
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) {
+		if status, ok := statusByKind[serviceErr.Kind]; ok {
+			if serviceErr.Err != nil {
+				log.Printf("%s: %v", serviceErr.Message, serviceErr.Err)
+			}
+			c.JSON(status, gin.H{"error": serviceErr.Message})
+			return
+		}
+	}
+
+	log.Printf("%s: %v", fallback, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
+
+// projectResponse converts a project to its API representation
+func projectResponse(project models.Project) models.ProjectResponse {
+	return models.ProjectResponse{
+		ID:          project.ID,
+		WorkspaceID: project.WorkspaceID,
+		Name:        project.Name,
+		Description: project.Description,
+		Color:       project.Color,
+		CreatedAt:   project.CreatedAt,
+	}
+}
+
+// timeEntryResponse converts a time entry to its API representation, with its project if loaded
+func timeEntryResponse(entry models.TimeEntry) models.TimeEntryResponse {
+	response := models.TimeEntryResponse{
+		ID:        entry.ID,
+		UserID:    entry.UserID,
+		StartTime: entry.StartTime,
+		EndTime:   entry.EndTime,
+		Duration:  entry.Duration,
+		CreatedAt: entry.CreatedAt,
+	}
+	if entry.Project != nil {
+		project := projectResponse(*entry.Project)
+		response.Project = &project
+	}
+	return response
+}
@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+	"time-tracker/models"
+	"time-tracker/services"
+	"time-tracker/stats"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TeamHandler serves the team leaderboard and report endpoints
+type TeamHandler struct {
+	db       *gorm.DB
+	projects *services.ProjectService // Checks the caller's workspace role
+}
+
+// NewTeamHandler returns a team handler using the database and project service
+func NewTeamHandler(db *gorm.DB, projects *services.ProjectService) *TeamHandler {
+	return &TeamHandler{db: db, projects: projects}
+}
+
+// rankedTeamsSQL ranks workspaces by the time their members tracked on or after a given local date
+// Ranks and positions follow rankedUsersSQL. Members who hid themselves from the
+// leaderboard do not count towards their team's total
+const rankedTeamsSQL = `
+	SELECT workspace_id,
+		total_seconds / 3600.0 AS total_hours,
+		RANK() OVER (ORDER BY total_seconds DESC) AS rank,
+		ROW_NUMBER() OVER (ORDER BY total_seconds DESC, workspace_id) AS position
+	FROM (
+		SELECT workspace_members.workspace_id, SUM(daily_user_totals.seconds) AS total_seconds
+		FROM workspace_members
+		JOIN workspaces ON workspaces.id = workspace_members.workspace_id
+		JOIN daily_user_totals ON daily_user_totals.user_id = workspace_members.user_id
+		WHERE daily_user_totals.local_date >= ?
+			AND workspaces.leaderboard_visible
+			AND workspace_members.user_id NOT IN (SELECT id FROM profiles WHERE leaderboard_visibility = 'hidden')
+		GROUP BY workspace_members.workspace_id
+		HAVING SUM(daily_user_totals.seconds) > 0
+	) totals
+`
+
+type rankedTeam struct {
+	WorkspaceID uuid.UUID
+	TotalHours  float32
+	Rank        int
+	Position    int
+}
+
+// Leaderboard returns teams ranked by the hours their members tracked in the requested period
+// Teams are the workspaces that opted in with leaderboard_visible.
+// Supports period=week|month|year|all and limit/offset pagination like GetLeaderboard
+func (h *TeamHandler) Leaderboard(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Periods follow the caller's timezone and week start day
+	var currentProfile models.Profile
+	h.db.Where("id = ?", userID).First(&currentProfile)
+
+	periodStart, ok := leaderboardPeriodStart(c.Query("period"), currentProfile, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period (use week, month, year or all)"})
+		return
+	}
+	since := periodStart.Format(stats.DateFormat)
+
+	limit, offset := offsetPagination(c, 5)
+
+	var total int64
+	if err := h.db.Raw(`SELECT COUNT(*) FROM (`+rankedTeamsSQL+`) ranked`, since).Scan(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team leaderboard"})
+		return
+	}
+
+	var results []rankedTeam
+	if err := h.db.Raw(`
+		SELECT * FROM (`+rankedTeamsSQL+`) ranked
+		WHERE position BETWEEN ? AND ?
+		ORDER BY position
+	`, since, offset+1, offset+limit).Scan(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team leaderboard"})
+		return
+	}
+
+	// Load names, member counts and the caller's memberships in a fixed number of queries
+	workspaceIDs := make([]uuid.UUID, 0, len(results))
+	for _, result := range results {
+		workspaceIDs = append(workspaceIDs, result.WorkspaceID)
+	}
+
+	var teams []models.Workspace
+	if err := h.db.Where("id IN ?", workspaceIDs).Find(&teams).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team leaderboard"})
+		return
+	}
+	namesByID := make(map[uuid.UUID]string, len(teams))
+	for _, team := range teams {
+		namesByID[team.ID] = team.Name
+	}
+
+	var memberCounts []struct {
+		WorkspaceID uuid.UUID
+		Members     int
+	}
+	if err := h.db.Model(&models.WorkspaceMember{}).
+		Select("workspace_id, COUNT(*) AS members").
+		Where("workspace_id IN ?", workspaceIDs).
+		Group("workspace_id").
+		Scan(&memberCounts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team leaderboard"})
+		return
+	}
+	membersByID := make(map[uuid.UUID]int, len(memberCounts))
+	for _, count := range memberCounts {
+		membersByID[count.WorkspaceID] = count.Members
+	}
+
+	var memberships []models.WorkspaceMember
+	if err := h.db.Where("user_id = ? AND workspace_id IN ?", userID, workspaceIDs).Find(&memberships).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team leaderboard"})
+		return
+	}
+	isMember := make(map[uuid.UUID]bool, len(memberships))
+	for _, membership := range memberships {
+		isMember[membership.WorkspaceID] = true
+	}
+
+	leaderboard := make([]models.TeamLeaderboardEntry, 0, len(results))
+	for _, result := range results {
+		entry := models.TeamLeaderboardEntry{
+			TeamID:        result.WorkspaceID,
+			Name:          namesByID[result.WorkspaceID],
+			TotalHours:    float32(int(result.TotalHours*10+0.5)) / 10,
+			MemberCount:   membersByID[result.WorkspaceID],
+			Rank:          result.Rank,
+			IsCurrentTeam: isMember[result.WorkspaceID],
+		}
+		if entry.MemberCount > 0 {
+			entry.AverageHours = float32(int(result.TotalHours/float32(entry.MemberCount)*10+0.5)) / 10
+		}
+		leaderboard = append(leaderboard, entry)
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, leaderboard)
+}
+
+// reportPeriodStart returns the start of the interval containing the local date
+func reportPeriodStart(day time.Time, interval string, weekStartDay int) time.Time {
+	switch interval {
+	case models.ReportIntervalWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) - weekStartDay + 7) % 7))
+	case models.ReportIntervalMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// Report returns each member's hours by project and by day, week or month for team leads
+// Supports period=week|month|year|all (default week) and interval=day|week|month (default day).
+// Only the team's own projects are broken down; other time is grouped under a nil project ID
+func (h *TeamHandler) Report(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	period := c.DefaultQuery("period", models.LeaderboardPeriodWeek)
+	interval := c.DefaultQuery("interval", models.ReportIntervalDay)
+	switch interval {
+	case models.ReportIntervalDay, models.ReportIntervalWeek, models.ReportIntervalMonth:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interval (use day, week or month)"})
+		return
+	}
+
+	// Team leads are the workspace's admins and owner
+	if _, err := h.projects.RequireWorkspaceRole(requestContext(c), id, userID, models.WorkspaceRoleAdmin); err != nil {
+		respondError(c, err, "Failed to fetch workspace membership")
+		return
+	}
+
+	// Periods follow the caller's timezone and week start day
+	var profile models.Profile
+	if err := h.db.Where("id = ?", userID).First(&profile).Error; err != nil {
+		profile = models.Profile{ID: userID, WeekStartDay: 1}
+	}
+
+	now := time.Now()
+	periodStart, ok := leaderboardPeriodStart(period, profile, now)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period (use week, month, year or all)"})
+		return
+	}
+	from := stats.LocalDate(periodStart, profile.Location())
+	to := stats.LocalDate(now, profile.Location())
+
+	var members []models.WorkspaceMember
+	if err := h.db.Where("workspace_id = ?", id).Order("created_at, user_id").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team report"})
+		return
+	}
+	memberIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.UserID)
+	}
+
+	var profiles []models.Profile
+	if err := h.db.Where("id IN ?", memberIDs).Find(&profiles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team report"})
+		return
+	}
+	namesByID := make(map[uuid.UUID]string, len(profiles))
+	for _, memberProfile := range profiles {
+		namesByID[memberProfile.ID] = memberProfile.Name
+	}
+
+	// Include soft-deleted projects so past time keeps its project name
+	var projects []models.Project
+	if err := h.db.Unscoped().Where("workspace_id = ?", id).Find(&projects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team report"})
+		return
+	}
+	projectsByID := make(map[uuid.UUID]models.Project, len(projects))
+	projectIDs := make([]uuid.UUID, 0, len(projects))
+	for _, project := range projects {
+		projectsByID[project.ID] = project
+		projectIDs = append(projectIDs, project.ID)
+	}
+
+	// Only time on the team's own projects is reported, never members' other work
+	var rows []models.DailyUserTotal
+	if err := h.db.Where("user_id IN ? AND project_id IN ? AND local_date BETWEEN ? AND ?", memberIDs, projectIDs, from.Format(stats.DateFormat), to.Format(stats.DateFormat)).
+		Order("local_date").
+		Find(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team report"})
+		return
+	}
+
+	projectSeconds := make(map[uuid.UUID]map[uuid.UUID]int64)
+	periodSeconds := make(map[uuid.UUID]map[time.Time]int64)
+	for _, row := range rows {
+		if projectSeconds[row.UserID] == nil {
+			projectSeconds[row.UserID] = make(map[uuid.UUID]int64)
+			periodSeconds[row.UserID] = make(map[time.Time]int64)
+		}
+		projectSeconds[row.UserID][row.ProjectID] += row.Seconds
+
+		day := time.Date(row.LocalDate.Year(), row.LocalDate.Month(), row.LocalDate.Day(), 0, 0, 0, 0, time.UTC)
+		periodSeconds[row.UserID][reportPeriodStart(day, interval, profile.WeekStartDay)] += row.Seconds
+	}
+
+	report := models.TeamReport{
+		TeamID:   id,
+		From:     from.Format(stats.DateFormat),
+		To:       to.Format(stats.DateFormat),
+		Interval: interval,
+		Members:  make([]models.TeamReportMember, 0, len(members)),
+	}
+
+	var teamSeconds int64
+	for _, member := range members {
+		reportMember := models.TeamReportMember{
+			UserID:   member.UserID,
+			Name:     namesByID[member.UserID],
+			Role:     member.Role,
+			Projects: []models.TeamReportProject{},
+			Periods:  []models.TeamReportPeriod{},
+		}
+
+		var memberSeconds int64
+		for projectID, seconds := range projectSeconds[member.UserID] {
+			memberSeconds += seconds
+			reportMember.Projects = append(reportMember.Projects, models.TeamReportProject{
+				ProjectID: projectID,
+				Name:      projectsByID[projectID].Name,
+				Hours:     secondsToHours(seconds),
+			})
+		}
+		// Most hours first; ties by name, then ID, so the order is stable
+		sort.Slice(reportMember.Projects, func(i, j int) bool {
+			a, b := reportMember.Projects[i], reportMember.Projects[j]
+			if a.Hours != b.Hours {
+				return a.Hours > b.Hours
+			}
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ProjectID.String() < b.ProjectID.String()
+		})
+
+		for start, seconds := range periodSeconds[member.UserID] {
+			reportMember.Periods = append(reportMember.Periods, models.TeamReportPeriod{
+				Start: start.Format(stats.DateFormat),
+				Hours: secondsToHours(seconds),
+			})
+		}
+		sort.Slice(reportMember.Periods, func(i, j int) bool {
+			return reportMember.Periods[i].Start < reportMember.Periods[j].Start
+		})
+
+		reportMember.TotalHours = secondsToHours(memberSeconds)
+		teamSeconds += memberSeconds
+		report.Members = append(report.Members, reportMember)
+	}
+	report.TotalHours = secondsToHours(teamSeconds)
+
+	c.JSON(http.StatusOK, report)
+}
+
+// secondsToHours converts seconds to hours rounded to one decimal place
+func secondsToHours(seconds int64) float32 {
+	return float32(int(float32(seconds)/3600*10+0.5)) / 10
+}
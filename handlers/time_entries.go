@@ -1,223 +1,127 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
-	"strconv"
-	"time"
-	"time-tracker/database"
+	"time-tracker/achievements"
 	"time-tracker/models"
+	"time-tracker/repository"
+	"time-tracker/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-func CreateTimeEntry(c *gin.Context) {
+// TimeEntryHandler serves the time entry endpoints
+type TimeEntryHandler struct {
+	entries *services.TimeEntryService
+}
+
+// NewTimeEntryHandler returns a time entry handler using the service
+func NewTimeEntryHandler(entries *services.TimeEntryService) *TimeEntryHandler {
+	return &TimeEntryHandler{entries: entries}
+}
+
+// Create starts a time entry on the requested project, or the user's General project
+func (h *TimeEntryHandler) Create(c *gin.Context) {
 	var req models.TimeEntryCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
-
-	uid := userID.(uuid.UUID)
-	projectID := req.ProjectID
-
-	// If no project ID provided, use "General" project
-	if projectID == nil {
-		var generalProject models.Project
-		err := database.DB.Where("user_id = ? AND name = ?", uid, "General").First(&generalProject).Error
-
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "General project not found. Please create a profile first."})
-			return
-		}
-		projectID = &generalProject.ID
-	}
-
-	timeEntry := models.TimeEntry{
-		UserID:      uid,
-		ProjectID:   projectID,
-		StartTime:   time.Now(),
-	}
-
-	if err := database.DB.Create(&timeEntry).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create time entry"})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	// Reload the time entry with project data
-	if err := database.DB.Preload("Project").First(&timeEntry, timeEntry.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch created time entry"})
+	entry, err := h.entries.Start(requestContext(c), userID, req.ProjectID)
+	if err != nil {
+		respondError(c, err, "Failed to create time entry")
 		return
 	}
 
-	response := models.TimeEntryResponse{
-		ID:        timeEntry.ID,
-		StartTime: timeEntry.StartTime,
-		EndTime:   timeEntry.EndTime,
-		Duration:  timeEntry.Duration,
-		CreatedAt: timeEntry.CreatedAt,
-	}
-
-	// Add project data if available
-	if timeEntry.Project != nil {
-		response.Project = &models.ProjectResponse{
-			ID:          timeEntry.Project.ID,
-			Name:        timeEntry.Project.Name,
-			Description: timeEntry.Project.Description,
-			Color:       timeEntry.Project.Color,
-			CreatedAt:   timeEntry.Project.CreatedAt,
-		}
-	}
-
-	c.JSON(http.StatusCreated, response)
+	c.JSON(http.StatusCreated, timeEntryResponse(entry))
 }
 
-func GetTimeEntries(c *gin.Context) {
-	// Get user ID from context (set by auth middleware)
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+// List returns the user's time entries, newest first, with page/limit pagination
+// With workspace_id, entries on the workspace's projects are listed:
+// everyone's for admins (optionally filtered by user_id), the caller's own otherwise
+func (h *TimeEntryHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
-	userID := userIDInterface.(uuid.UUID)
 
-	// Parse pagination parameters
-	page := 1
-	limit := 10 // default limit
+	page, limit := pagination(c, 10)
+	opts := services.TimeEntryListOptions{Page: repository.Page{Number: page, Limit: limit}}
 
-	if pageStr := c.Query("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
+	if workspaceIDStr := c.Query("workspace_id"); workspaceIDStr != "" {
+		workspaceID, err := uuid.Parse(workspaceIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+			return
 		}
-	}
+		opts.WorkspaceID = &workspaceID
 
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
+		if memberIDStr := c.Query("user_id"); memberIDStr != "" {
+			memberID, err := uuid.Parse(memberIDStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+				return
+			}
+			opts.MemberID = &memberID
 		}
 	}
 
-	log.Printf("GetTimeEntries pagination: page=%d, limit=%d, offset=%d", page, limit, (page-1)*limit)
-
-	// Calculate offset
-	offset := (page - 1) * limit
-
-	// Get total count
-	var total int64
-	if err := database.DB.Model(&models.TimeEntry{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count time entries"})
-		return
-	}
-
-	// Get paginated time entries
-	var timeEntries []models.TimeEntry
-	if err := database.DB.Preload("Project").Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&timeEntries).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch time entries"})
+	timeEntries, total, err := h.entries.List(requestContext(c), userID, opts)
+	if err != nil {
+		respondError(c, err, "Failed to fetch time entries")
 		return
 	}
 
-	// Convert to response format
-	var data []models.TimeEntryResponse
+	data := make([]models.TimeEntryResponse, 0, len(timeEntries))
 	for _, entry := range timeEntries {
-		response := models.TimeEntryResponse{
-			ID:        entry.ID,
-			StartTime: entry.StartTime,
-			EndTime:   entry.EndTime,
-			Duration:  entry.Duration,
-			CreatedAt: entry.CreatedAt,
-		}
-
-		// Add project data if available
-		if entry.Project != nil {
-			response.Project = &models.ProjectResponse{
-				ID:          entry.Project.ID,
-				Name:        entry.Project.Name,
-				Description: entry.Project.Description,
-				Color:       entry.Project.Color,
-				CreatedAt:   entry.Project.CreatedAt,
-			}
-		}
-
-		data = append(data, response)
+		data = append(data, timeEntryResponse(entry))
 	}
 
-	// Calculate total pages
-	totalPages := int((total + int64(limit) - 1) / int64(limit))
-
-	response := models.PaginatedTimeEntriesResponse{
+	c.JSON(http.StatusOK, models.PaginatedTimeEntriesResponse{
 		Data:       data,
 		Page:       page,
 		Limit:      limit,
 		Total:      total,
-		TotalPages: totalPages,
-	}
-
-	c.JSON(http.StatusOK, response)
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	})
 }
 
-func GetTimeEntry(c *gin.Context) {
-	// Get user ID from context (set by auth middleware)
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+// Get returns one time entry
+// Workspace admins can also see other members' entries on shared projects
+func (h *TimeEntryHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
-	userID := userIDInterface.(uuid.UUID)
-
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	id, ok := pathID(c)
+	if !ok {
 		return
 	}
 
-	var timeEntry models.TimeEntry
-	if err := database.DB.Preload("Project").Where("id = ? AND user_id = ?", id, userID).First(&timeEntry).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Time entry not found"})
+	entry, err := h.entries.Get(requestContext(c), userID, id)
+	if err != nil {
+		respondError(c, err, "Failed to fetch time entry")
 		return
 	}
 
-	response := models.TimeEntryResponse{
-		ID:        timeEntry.ID,
-		StartTime: timeEntry.StartTime,
-		EndTime:   timeEntry.EndTime,
-		Duration:  timeEntry.Duration,
-		CreatedAt: timeEntry.CreatedAt,
-	}
-
-	// Add project data if available
-	if timeEntry.Project != nil {
-		response.Project = &models.ProjectResponse{
-			ID:          timeEntry.Project.ID,
-			Name:        timeEntry.Project.Name,
-			Description: timeEntry.Project.Description,
-			Color:       timeEntry.Project.Color,
-			CreatedAt:   timeEntry.Project.CreatedAt,
-		}
-	}
-
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, timeEntryResponse(entry))
 }
 
-func UpdateTimeEntry(c *gin.Context) {
-	// Get user ID from context (set by auth middleware)
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+// Update moves a time entry to another project and/or sets its end time
+func (h *TimeEntryHandler) Update(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
-	userID := userIDInterface.(uuid.UUID)
-
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	id, ok := pathID(c)
+	if !ok {
 		return
 	}
 
@@ -227,152 +131,68 @@ func UpdateTimeEntry(c *gin.Context) {
 		return
 	}
 
-	var timeEntry models.TimeEntry
-	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&timeEntry).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Time entry not found"})
-		return
-	}
-
-	// Update fields
-	if req.ProjectID != nil {
-		timeEntry.ProjectID = req.ProjectID
-	}
-	if req.EndTime != "" {
-		endTime, err := time.Parse(time.RFC3339, req.EndTime)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end time format"})
-			return
-		}
-		// Validate that end time is after start time
-		if endTime.Before(timeEntry.StartTime) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "End time cannot be before start time"})
-			return
-		}
-		timeEntry.EndTime = &endTime
-		timeEntry.Duration = int64(endTime.Sub(timeEntry.StartTime).Seconds())
-	}
-
-	if err := database.DB.Save(&timeEntry).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update time entry"})
-		return
-	}
-
-	// Reload the time entry with project data
-	if err := database.DB.Preload("Project").First(&timeEntry, timeEntry.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated time entry"})
+	entry, unlocked, err := h.entries.Update(requestContext(c), userID, id, req)
+	if err != nil {
+		respondError(c, err, "Failed to update time entry")
 		return
 	}
 
-	response := models.TimeEntryResponse{
-		ID:        timeEntry.ID,
-		StartTime: timeEntry.StartTime,
-		EndTime:   timeEntry.EndTime,
-		Duration:  timeEntry.Duration,
-		CreatedAt: timeEntry.CreatedAt,
-	}
-
-	// Add project data if available
-	if timeEntry.Project != nil {
-		response.Project = &models.ProjectResponse{
-			ID:          timeEntry.Project.ID,
-			Name:        timeEntry.Project.Name,
-			Description: timeEntry.Project.Description,
-			Color:       timeEntry.Project.Color,
-			CreatedAt:   timeEntry.Project.CreatedAt,
-		}
-	}
+	// Setting an end time stops the entry, which can unlock achievements
+	response := timeEntryResponse(entry)
+	response.UnlockedAchievements = unlockedAchievementResponses(unlocked)
 
 	c.JSON(http.StatusOK, response)
 }
 
-func StopTimeEntry(c *gin.Context) {
-	// Get user ID from context (set by auth middleware)
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
-	userID := userIDInterface.(uuid.UUID)
-
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
-		return
-	}
-
-	var timeEntry models.TimeEntry
-	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&timeEntry).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Time entry not found"})
+// Stop ends a running time entry now
+func (h *TimeEntryHandler) Stop(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
-
-	if timeEntry.EndTime != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Time entry already stopped"})
-		return
-	}
-
-	now := time.Now()
-	timeEntry.EndTime = &now
-	timeEntry.Duration = int64(now.Sub(timeEntry.StartTime).Seconds())
-
-	if err := database.DB.Save(&timeEntry).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop time entry"})
+	id, ok := pathID(c)
+	if !ok {
 		return
 	}
 
-	// Reload the time entry with project data
-	if err := database.DB.Preload("Project").First(&timeEntry, timeEntry.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stopped time entry"})
+	entry, unlocked, err := h.entries.Stop(requestContext(c), userID, id)
+	if err != nil {
+		respondError(c, err, "Failed to stop time entry")
 		return
 	}
 
-	response := models.TimeEntryResponse{
-		ID:        timeEntry.ID,
-		StartTime: timeEntry.StartTime,
-		EndTime:   timeEntry.EndTime,
-		Duration:  timeEntry.Duration,
-		CreatedAt: timeEntry.CreatedAt,
-	}
-
-	// Add project data if available
-	if timeEntry.Project != nil {
-		response.Project = &models.ProjectResponse{
-			ID:          timeEntry.Project.ID,
-			Name:        timeEntry.Project.Name,
-			Description: timeEntry.Project.Description,
-			Color:       timeEntry.Project.Color,
-			CreatedAt:   timeEntry.Project.CreatedAt,
-		}
-	}
+	response := timeEntryResponse(entry)
+	response.UnlockedAchievements = unlockedAchievementResponses(unlocked)
 
 	c.JSON(http.StatusOK, response)
 }
 
-func DeleteTimeEntry(c *gin.Context) {
-	// Get user ID from context (set by auth middleware)
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
-	userID := userIDInterface.(uuid.UUID)
-
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+// Delete removes a time entry
+func (h *TimeEntryHandler) Delete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
-
-	result := database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.TimeEntry{})
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete time entry"})
+	id, ok := pathID(c)
+	if !ok {
 		return
 	}
 
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Time entry not found"})
+	if err := h.entries.Delete(requestContext(c), userID, id); err != nil {
+		respondError(c, err, "Failed to delete time entry")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Time entry deleted successfully"})
 }
+
+// unlockedAchievementResponses converts newly unlocked achievements to their API representation
+func unlockedAchievementResponses(unlocked []models.Achievement) []models.AchievementResponse {
+	responses := make([]models.AchievementResponse, 0, len(unlocked))
+	for _, achievement := range unlocked {
+		response := achievements.ToResponse(achievement)
+		response.Unlocked = true
+		responses = append(responses, response)
+	}
+	return responses
+}
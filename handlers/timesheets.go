@@ -0,0 +1,434 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+	"time-tracker/models"
+	"time-tracker/stats"
+	"time-tracker/timesheets"
+	"time-tracker/workspaces"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TimesheetHandler serves the timesheet endpoints
+type TimesheetHandler struct {
+	db *gorm.DB
+}
+
+// NewTimesheetHandler returns a timesheet handler using the database
+func NewTimesheetHandler(db *gorm.DB) *TimesheetHandler {
+	return &TimesheetHandler{db: db}
+}
+
+// timesheetResponse builds the response for a timesheet, totalling the entries of its week
+func timesheetResponse(db *gorm.DB, timesheet models.Timesheet) (models.TimesheetResponse, error) {
+	data, err := timesheetResponses(db, []models.Timesheet{timesheet})
+	if err != nil {
+		return models.TimesheetResponse{}, err
+	}
+	return data[0], nil
+}
+
+// timesheetResponses builds the responses for a list of timesheets
+// The owners' profiles and the entries of all the weeks are loaded in one query each
+func timesheetResponses(db *gorm.DB, list []models.Timesheet) ([]models.TimesheetResponse, error) {
+	data := make([]models.TimesheetResponse, 0, len(list))
+	if len(list) == 0 {
+		return data, nil
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(list))
+	for _, timesheet := range list {
+		userIDs = append(userIDs, timesheet.UserID)
+	}
+
+	// Weeks follow their owner's timezone
+	var profiles []models.Profile
+	if err := db.Where("id IN ?", userIDs).Find(&profiles).Error; err != nil {
+		return nil, err
+	}
+	locations := make(map[uuid.UUID]*time.Location, len(profiles))
+	for _, profile := range profiles {
+		locations[profile.ID] = profile.Location()
+	}
+
+	type week struct {
+		start    time.Time
+		from, to time.Time
+	}
+	weeks := make([]week, 0, len(list))
+	var from, to time.Time
+	for i, timesheet := range list {
+		loc, ok := locations[timesheet.UserID]
+		if !ok {
+			loc = time.UTC
+		}
+		start := stats.LocalDate(timesheet.WeekStart, time.UTC)
+		weekFrom, weekTo := timesheets.Bounds(start, loc)
+		weeks = append(weeks, week{start: start, from: weekFrom, to: weekTo})
+		if i == 0 || weekFrom.Before(from) {
+			from = weekFrom
+		}
+		if i == 0 || weekTo.After(to) {
+			to = weekTo
+		}
+	}
+
+	var entries []models.TimeEntry
+	if err := db.Select("user_id, start_time, duration").
+		Where("user_id IN ? AND start_time >= ? AND start_time < ?", userIDs, from, to).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	entriesByUser := make(map[uuid.UUID][]models.TimeEntry)
+	for _, entry := range entries {
+		entriesByUser[entry.UserID] = append(entriesByUser[entry.UserID], entry)
+	}
+
+	for i, timesheet := range list {
+		var seconds int64
+		count := 0
+		for _, entry := range entriesByUser[timesheet.UserID] {
+			if !entry.StartTime.Before(weeks[i].from) && entry.StartTime.Before(weeks[i].to) {
+				seconds += entry.Duration
+				count++
+			}
+		}
+
+		data = append(data, models.TimesheetResponse{
+			ID:          timesheet.ID,
+			UserID:      timesheet.UserID,
+			WeekStart:   weeks[i].start.Format(stats.DateFormat),
+			WeekEnd:     weeks[i].start.AddDate(0, 0, 6).Format(stats.DateFormat),
+			Status:      timesheet.Status,
+			TotalHours:  secondsToHours(seconds),
+			Entries:     count,
+			SubmittedAt: timesheet.SubmittedAt,
+			ReviewedBy:  timesheet.ReviewedBy,
+			ReviewedAt:  timesheet.ReviewedAt,
+			Comment:     timesheet.Comment,
+		})
+	}
+	return data, nil
+}
+
+// Submit submits the user's timesheet for a week that has ended, the previous one by default
+// A rejected timesheet can be submitted again
+func (h *TimesheetHandler) Submit(c *gin.Context) {
+	var req models.SubmitTimesheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Weeks follow the user's timezone and week start day
+	var profile models.Profile
+	if err := h.db.Where("id = ?", userID).First(&profile).Error; err != nil {
+		profile = models.Profile{ID: userID, WeekStartDay: 1}
+	}
+
+	// Only weeks that have ended can be submitted, so approving one never freezes days not yet worked
+	now := time.Now()
+	weekStart := timesheets.WeekStart(profile, now).AddDate(0, 0, -7)
+	if req.WeekStart != "" {
+		parsed, err := time.Parse(stats.DateFormat, req.WeekStart)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid week_start format (use YYYY-MM-DD)"})
+			return
+		}
+		if int(parsed.Weekday()) != profile.WeekStartDay {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "week_start must be the first day of a week"})
+			return
+		}
+		weekStart = parsed
+	}
+	if _, weekEnd := timesheets.Bounds(weekStart, profile.Location()); weekEnd.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot submit a week that has not ended"})
+		return
+	}
+
+	// Every entry of the week must be stopped so the submitted totals are final
+	var running int64
+	if err := h.db.Model(&models.TimeEntry{}).
+		Scopes(timesheets.Entries(userID, weekStart, profile.Location())).
+		Where("end_time IS NULL").
+		Count(&running).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check running time entries"})
+		return
+	}
+	if running > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Stop running time entries before submitting the week"})
+		return
+	}
+
+	var timesheet models.Timesheet
+	err := h.db.Where("user_id = ? AND week_start = ?", userID, weekStart.Format(stats.DateFormat)).First(&timesheet).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timesheet"})
+		return
+	}
+	if err == nil && timesheet.Status != models.TimesheetRejected {
+		c.JSON(http.StatusConflict, gin.H{"error": "Timesheet already " + timesheet.Status})
+		return
+	}
+
+	timesheet.UserID = userID
+	timesheet.WeekStart = weekStart
+	timesheet.Status = models.TimesheetSubmitted
+	timesheet.SubmittedAt = now
+	timesheet.ReviewedBy = nil
+	timesheet.ReviewedAt = nil
+	timesheet.Comment = ""
+
+	// Save the timesheet and record the submission together
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&timesheet).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.TimesheetEvent{
+			TimesheetID: timesheet.ID,
+			ActorID:     userID,
+			Action:      models.TimesheetSubmitted,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit timesheet"})
+		return
+	}
+
+	response, err := timesheetResponse(h.db, timesheet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timesheet totals"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// List returns the current user's timesheets, newest week first
+// Supports an optional status filter
+func (h *TimesheetHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	query := h.db.Where("user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var list []models.Timesheet
+	if err := query.Order("week_start DESC").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timesheets"})
+		return
+	}
+
+	data, err := timesheetResponses(h.db, list)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timesheet totals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+// ListPending returns the submitted timesheets the current user can review,
+// oldest submission first
+func (h *TimesheetHandler) ListPending(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var list []models.Timesheet
+	err := h.db.Where("status = ? AND user_id IN (?)", models.TimesheetSubmitted, workspaces.ManagedUserIDs(h.db, userID)).
+		Order("submitted_at").
+		Find(&list).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timesheets"})
+		return
+	}
+
+	data, err := timesheetResponses(h.db, list)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timesheet totals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+// Get returns a timesheet with its submission and review history
+// Visible to its owner and to their managers
+func (h *TimesheetHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var timesheet models.Timesheet
+	if err := h.db.Where("id = ?", id).First(&timesheet).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Timesheet not found"})
+		return
+	}
+	if timesheet.UserID != userID {
+		manages, err := workspaces.Manages(h.db, userID, timesheet.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace membership"})
+			return
+		}
+		if !manages {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Timesheet not found"})
+			return
+		}
+	}
+
+	var events []models.TimesheetEvent
+	if err := h.db.Where("timesheet_id = ?", timesheet.ID).Order("created_at").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timesheet history"})
+		return
+	}
+
+	response, err := timesheetResponse(h.db, timesheet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timesheet totals"})
+		return
+	}
+	response.Events = make([]models.TimesheetEventResponse, 0, len(events))
+	for _, event := range events {
+		response.Events = append(response.Events, models.TimesheetEventResponse{
+			ActorID:   event.ActorID,
+			Action:    event.Action,
+			Comment:   event.Comment,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TimesheetHandler) Approve(c *gin.Context) {
+	h.review(c, models.TimesheetApproved)
+}
+
+func (h *TimesheetHandler) Reject(c *gin.Context) {
+	h.review(c, models.TimesheetRejected)
+}
+
+// review approves or rejects a submitted timesheet
+// Only the user's managers can review it, never the user themselves, and only once its week has ended;
+// rejections need a comment
+func (h *TimesheetHandler) review(c *gin.Context, status string) {
+	var req models.ReviewTimesheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if status == models.TimesheetRejected && req.Comment == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A comment is required to reject a timesheet"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var timesheet models.Timesheet
+	if err := h.db.Where("id = ?", id).First(&timesheet).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Timesheet not found"})
+		return
+	}
+	if timesheet.UserID == userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot review your own timesheet"})
+		return
+	}
+	manages, err := workspaces.Manages(h.db, userID, timesheet.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace membership"})
+		return
+	}
+	if !manages {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Timesheet not found"})
+		return
+	}
+	if timesheet.Status != models.TimesheetSubmitted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Timesheet is " + timesheet.Status + ", not submitted"})
+		return
+	}
+
+	// Weeks submitted before they ended cannot be reviewed until they have
+	now := time.Now()
+	weekStart := stats.LocalDate(timesheet.WeekStart, time.UTC)
+	if _, weekEnd := timesheets.Bounds(weekStart, stats.UserLocation(h.db, timesheet.UserID)); weekEnd.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot review a week that has not ended"})
+		return
+	}
+
+	timesheet.Status = status
+	timesheet.ReviewedBy = &userID
+	timesheet.ReviewedAt = &now
+	timesheet.Comment = req.Comment
+
+	// Update the status and record the review together
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Timesheet{}).
+			Where("id = ? AND status = ?", timesheet.ID, models.TimesheetSubmitted).
+			Updates(map[string]interface{}{
+				"status":      timesheet.Status,
+				"reviewed_by": userID,
+				"reviewed_at": now,
+				"comment":     timesheet.Comment,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Create(&models.TimesheetEvent{
+			TimesheetID: timesheet.ID,
+			ActorID:     userID,
+			Action:      status,
+			Comment:     req.Comment,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Someone else reviewed it in the meantime
+		c.JSON(http.StatusConflict, gin.H{"error": "Timesheet is no longer submitted"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review timesheet"})
+		return
+	}
+
+	response, err := timesheetResponse(h.db, timesheet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timesheet totals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"time-tracker/middleware"
+	"time-tracker/models"
+	"time-tracker/tokens"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TokenHandler serves the personal access token endpoints
+type TokenHandler struct {
+	db *gorm.DB
+}
+
+// NewTokenHandler returns a personal access token handler using the database
+func NewTokenHandler(db *gorm.DB) *TokenHandler {
+	return &TokenHandler{db: db}
+}
+
+// rejectTokenAuth keeps personal access tokens from managing tokens,
+// so a leaked token cannot mint new ones with wider scopes
+func rejectTokenAuth(c *gin.Context) bool {
+	if c.GetString("auth_method") == middleware.AuthMethodPersonalAccessToken {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Personal access tokens cannot manage tokens"})
+		return false
+	}
+	return true
+}
+
+func personalAccessTokenResponse(pat models.PersonalAccessToken) models.PersonalAccessTokenResponse {
+	return models.PersonalAccessTokenResponse{
+		ID:         pat.ID,
+		Name:       pat.Name,
+		Prefix:     pat.Prefix,
+		Scopes:     tokens.Scopes(pat),
+		ExpiresAt:  pat.ExpiresAt,
+		LastUsedAt: pat.LastUsedAt,
+		CreatedAt:  pat.CreatedAt,
+	}
+}
+
+// Create creates a token for the current user
+// The token itself is only returned in this response
+func (h *TokenHandler) Create(c *gin.Context) {
+	var req models.PersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if !rejectTokenAuth(c) {
+		return
+	}
+
+	token, hash, prefix, err := tokens.Generate()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	// Store each scope once, in a stable order
+	seen := map[string]bool{}
+	var scopes []string
+	for _, scope := range req.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	pat := models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      req.Name,
+		TokenHash: hash,
+		Prefix:    prefix,
+		Scopes:    strings.Join(scopes, " "),
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		pat.ExpiresAt = &expiresAt
+	}
+
+	if err := h.db.Create(&pat).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	response := personalAccessTokenResponse(pat)
+	response.Token = token
+	c.JSON(http.StatusCreated, response)
+}
+
+// List returns the current user's tokens, newest first
+func (h *TokenHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if !rejectTokenAuth(c) {
+		return
+	}
+
+	var list []models.PersonalAccessToken
+	if err := h.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tokens"})
+		return
+	}
+
+	data := make([]models.PersonalAccessTokenResponse, 0, len(list))
+	for _, pat := range list {
+		data = append(data, personalAccessTokenResponse(pat))
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+// Delete revokes one of the current user's tokens
+func (h *TokenHandler) Delete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if !rejectTokenAuth(c) {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	result := h.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.PersonalAccessToken{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
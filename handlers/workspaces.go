@@ -0,0 +1,586 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"time-tracker/models"
+	"time-tracker/services"
+	"time-tracker/workspaces"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkspaceHandler serves the workspace endpoints
+type WorkspaceHandler struct {
+	db       *gorm.DB
+	projects *services.ProjectService // Checks the caller's workspace role
+}
+
+// NewWorkspaceHandler returns a workspace handler using the database and project service
+func NewWorkspaceHandler(db *gorm.DB, projects *services.ProjectService) *WorkspaceHandler {
+	return &WorkspaceHandler{db: db, projects: projects}
+}
+
+func (h *WorkspaceHandler) Create(c *gin.Context) {
+	var req models.WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	workspace := models.Workspace{Name: req.Name}
+	if req.LeaderboardVisible != nil {
+		workspace.LeaderboardVisible = *req.LeaderboardVisible
+	}
+
+	// The creator becomes the owner
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&workspace).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      userID,
+			Role:        models.WorkspaceRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workspace"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.WorkspaceResponse{
+		ID:                 workspace.ID,
+		Name:               workspace.Name,
+		Role:               models.WorkspaceRoleOwner,
+		LeaderboardVisible: workspace.LeaderboardVisible,
+		CreatedAt:          workspace.CreatedAt,
+	})
+}
+
+// List returns the workspaces the current user belongs to
+func (h *WorkspaceHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var data []models.WorkspaceResponse
+	if err := h.db.Table("workspaces").
+		Select("workspaces.id, workspaces.name, workspace_members.role, workspaces.leaderboard_visible, workspaces.created_at").
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID).
+		Order("workspaces.created_at").
+		Scan(&data).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspaces"})
+		return
+	}
+	if data == nil {
+		data = []models.WorkspaceResponse{}
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+func (h *WorkspaceHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	role, err := h.projects.RequireWorkspaceRole(requestContext(c), id, userID, models.WorkspaceRoleViewer)
+	if err != nil {
+		respondError(c, err, "Failed to fetch workspace membership")
+		return
+	}
+
+	var workspace models.Workspace
+	if err := h.db.Where("id = ?", id).First(&workspace).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.WorkspaceResponse{
+		ID:                 workspace.ID,
+		Name:               workspace.Name,
+		Role:               role,
+		LeaderboardVisible: workspace.LeaderboardVisible,
+		CreatedAt:          workspace.CreatedAt,
+	})
+}
+
+func (h *WorkspaceHandler) Update(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req models.WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.projects.RequireWorkspaceRole(requestContext(c), id, userID, models.WorkspaceRoleAdmin)
+	if err != nil {
+		respondError(c, err, "Failed to fetch workspace membership")
+		return
+	}
+
+	var workspace models.Workspace
+	if err := h.db.Where("id = ?", id).First(&workspace).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
+
+	workspace.Name = req.Name
+	if req.LeaderboardVisible != nil {
+		workspace.LeaderboardVisible = *req.LeaderboardVisible
+	}
+	if err := h.db.Save(&workspace).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workspace"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.WorkspaceResponse{
+		ID:                 workspace.ID,
+		Name:               workspace.Name,
+		Role:               role,
+		LeaderboardVisible: workspace.LeaderboardVisible,
+		CreatedAt:          workspace.CreatedAt,
+	})
+}
+
+// Delete deletes a workspace; only the owner can do this and only once it has no projects left
+func (h *WorkspaceHandler) Delete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.projects.RequireWorkspaceRole(requestContext(c), id, userID, models.WorkspaceRoleOwner); err != nil {
+		respondError(c, err, "Failed to fetch workspace membership")
+		return
+	}
+
+	var projects int64
+	if err := h.db.Model(&models.Project{}).Where("workspace_id = ?", id).Count(&projects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count workspace projects"})
+		return
+	}
+	if projects > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Delete the workspace's projects first"})
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workspace_id = ?", id).Delete(&models.WorkspaceInvitation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workspace_id = ?", id).Delete(&models.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+		// Soft-deleted projects still reference the workspace
+		if err := tx.Unscoped().Model(&models.Project{}).Where("workspace_id = ?", id).Update("workspace_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&models.Workspace{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete workspace"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Workspace deleted successfully"})
+}
+
+// ListMembers returns the members of a workspace with their roles
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.projects.RequireWorkspaceRole(requestContext(c), id, userID, models.WorkspaceRoleViewer); err != nil {
+		respondError(c, err, "Failed to fetch workspace membership")
+		return
+	}
+
+	var members []models.WorkspaceMember
+	if err := h.db.Where("workspace_id = ?", id).Order("created_at").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace members"})
+		return
+	}
+
+	memberIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.UserID)
+	}
+
+	var users []models.User
+	if err := h.db.Where("id IN ?", memberIDs).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace members"})
+		return
+	}
+	usersByID := make(map[uuid.UUID]models.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	var profiles []models.Profile
+	if err := h.db.Where("id IN ?", memberIDs).Find(&profiles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace members"})
+		return
+	}
+	profilesByID := make(map[uuid.UUID]models.Profile, len(profiles))
+	for _, profile := range profiles {
+		profilesByID[profile.ID] = profile
+	}
+
+	data := make([]models.WorkspaceMemberResponse, 0, len(members))
+	for _, member := range members {
+		profile := profilesByID[member.UserID]
+		data = append(data, models.WorkspaceMemberResponse{
+			UserID:            member.UserID,
+			Name:              profile.Name,
+			Email:             usersByID[member.UserID].Email,
+			ProfilePictureURL: profile.ProfilePictureURL,
+			Role:              member.Role,
+			JoinedAt:          member.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+// UpdateMember changes a member's role
+// Callers can only manage members below their own role and grant roles below it
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req models.UpdateWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.projects.RequireWorkspaceRole(requestContext(c), id, userID, models.WorkspaceRoleAdmin)
+	if err != nil {
+		respondError(c, err, "Failed to fetch workspace membership")
+		return
+	}
+
+	var member models.WorkspaceMember
+	if err := h.db.Where("workspace_id = ? AND user_id = ?", id, memberID).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if !workspaces.Outranks(role, member.Role) || !workspaces.Outranks(role, req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient workspace role"})
+		return
+	}
+
+	if err := h.db.Model(&models.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", id, memberID).Update("role", req.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+	member.Role = req.Role
+
+	c.JSON(http.StatusOK, gin.H{"user_id": member.UserID, "role": member.Role})
+}
+
+// RemoveMember removes a member from a workspace
+// Members can always leave, except the owner; admins can remove members below their role
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	role, err := h.projects.RequireWorkspaceRole(requestContext(c), id, userID, models.WorkspaceRoleViewer)
+	if err != nil {
+		respondError(c, err, "Failed to fetch workspace membership")
+		return
+	}
+
+	var member models.WorkspaceMember
+	if err := h.db.Where("workspace_id = ? AND user_id = ?", id, memberID).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	switch {
+	case member.Role == models.WorkspaceRoleOwner:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The owner cannot leave the workspace"})
+		return
+	case memberID != userID && (!workspaces.AtLeast(role, models.WorkspaceRoleAdmin) || !workspaces.Outranks(role, member.Role)):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient workspace role"})
+		return
+	}
+
+	if err := h.db.Where("workspace_id = ? AND user_id = ?", id, memberID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// CreateInvitation invites an email address to the workspace
+// The accept token is only returned in this response
+func (h *WorkspaceHandler) CreateInvitation(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req models.WorkspaceInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.WorkspaceRoleMember
+	}
+
+	role, err := h.projects.RequireWorkspaceRole(requestContext(c), id, userID, models.WorkspaceRoleAdmin)
+	if err != nil {
+		respondError(c, err, "Failed to fetch workspace membership")
+		return
+	}
+	if !workspaces.Outranks(role, req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient workspace role"})
+		return
+	}
+
+	token, tokenHash, err := workspaces.NewInvitationToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	invitation := models.WorkspaceInvitation{
+		WorkspaceID: id,
+		Email:       strings.ToLower(req.Email),
+		Role:        req.Role,
+		TokenHash:   tokenHash,
+		InvitedBy:   userID,
+		ExpiresAt:   time.Now().Add(workspaces.InvitationTTL),
+	}
+
+	if err := h.db.Create(&invitation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.WorkspaceInvitationResponse{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+		Token:     token,
+	})
+}
+
+// ListInvitations returns the workspace's pending invitations
+func (h *WorkspaceHandler) ListInvitations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.projects.RequireWorkspaceRole(requestContext(c), id, userID, models.WorkspaceRoleAdmin); err != nil {
+		respondError(c, err, "Failed to fetch workspace membership")
+		return
+	}
+
+	var invitations []models.WorkspaceInvitation
+	if err := h.db.Where("workspace_id = ? AND accepted_at IS NULL AND expires_at > ?", id, time.Now()).
+		Order("created_at DESC").Find(&invitations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	data := make([]models.WorkspaceInvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		data = append(data, models.WorkspaceInvitationResponse{
+			ID:        invitation.ID,
+			Email:     invitation.Email,
+			Role:      invitation.Role,
+			ExpiresAt: invitation.ExpiresAt,
+			CreatedAt: invitation.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+// DeleteInvitation revokes a pending invitation
+func (h *WorkspaceHandler) DeleteInvitation(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	invitationID, err := uuid.Parse(c.Param("invitation_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	if _, err := h.projects.RequireWorkspaceRole(requestContext(c), id, userID, models.WorkspaceRoleAdmin); err != nil {
+		respondError(c, err, "Failed to fetch workspace membership")
+		return
+	}
+
+	result := h.db.Where("id = ? AND workspace_id = ? AND accepted_at IS NULL", invitationID, id).Delete(&models.WorkspaceInvitation{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete invitation"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation deleted successfully"})
+}
+
+// AcceptInvitation adds the current user to a workspace using an invitation token
+// The invitation must be addressed to the user's email
+func (h *WorkspaceHandler) AcceptInvitation(c *gin.Context) {
+	var req models.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var invitation models.WorkspaceInvitation
+	if err := h.db.Where("token_hash = ? AND accepted_at IS NULL AND expires_at > ?", workspaces.HashToken(req.Token), time.Now()).
+		First(&invitation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found or expired"})
+		return
+	}
+
+	var user models.User
+	if err := h.db.Where("id = ?", userID).First(&user).Error; err != nil || !strings.EqualFold(user.Email, invitation.Email) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invitation was sent to a different email address"})
+		return
+	}
+
+	role, err := workspaces.Role(h.db, invitation.WorkspaceID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+	if role != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Already a member of this workspace"})
+		return
+	}
+
+	now := time.Now()
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.WorkspaceMember{
+			WorkspaceID: invitation.WorkspaceID,
+			UserID:      userID,
+			Role:        invitation.Role,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&invitation).Update("accepted_at", now).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	var workspace models.Workspace
+	if err := h.db.Where("id = ?", invitation.WorkspaceID).First(&workspace).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workspace"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.WorkspaceResponse{
+		ID:                 workspace.ID,
+		Name:               workspace.Name,
+		Role:               invitation.Role,
+		LeaderboardVisible: workspace.LeaderboardVisible,
+		CreatedAt:          workspace.CreatedAt,
+	})
+}
@@ -0,0 +1,254 @@
+package jobs
+
+import (
+	"errors"
+	"log"
+	"time"
+	"time-tracker/audit"
+	"time-tracker/models"
+	"time-tracker/periodlocks"
+	"time-tracker/stats"
+	"time-tracker/timesheets"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PictureDeleter removes profile pictures from storage
+type PictureDeleter interface {
+	DeleteProfilePicture(publicURL string) error
+}
+
+// AccountDeletion purges accounts whose deletion grace period has expired
+type AccountDeletion struct {
+	db      *gorm.DB
+	storage PictureDeleter
+}
+
+// NewAccountDeletion returns the account deletion job for the database and picture storage
+func NewAccountDeletion(db *gorm.DB, storage PictureDeleter) *AccountDeletion {
+	return &AccountDeletion{db: db, storage: storage}
+}
+
+// Start periodically purges accounts whose grace period has expired
+func (j *AccountDeletion) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			j.PurgeDueAccounts()
+			<-ticker.C
+		}
+	}()
+
+	log.Printf("Account deletion worker started (interval: %s)", interval)
+}
+
+// PurgeDueAccounts hard-deletes all accounts whose scheduled deletion time has passed
+func (j *AccountDeletion) PurgeDueAccounts() {
+	var profiles []models.Profile
+	if err := j.db.Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", time.Now()).Find(&profiles).Error; err != nil {
+		log.Printf("Failed to fetch accounts scheduled for deletion: %v", err)
+		return
+	}
+
+	for _, profile := range profiles {
+		if err := j.PurgeAccount(profile); err != nil {
+			log.Printf("Failed to purge account %s: %v", profile.ID, err)
+			continue
+		}
+		log.Printf("Purged account %s", profile.ID)
+	}
+}
+
+// PurgeAccount removes the profile picture from storage and hard-deletes
+// the user's audit trail, time entries, goals, timesheets, personal projects, memberships, access tokens, friendships and profile,
+// along with workspaces they are the only member of
+func (j *AccountDeletion) PurgeAccount(profile models.Profile) error {
+	// Delete the picture first so a storage failure leaves the account
+	// in place for the next run instead of leaking the file
+	if profile.ProfilePictureURL != nil && *profile.ProfilePictureURL != "" {
+		if err := j.storage.DeleteProfilePicture(*profile.ProfilePictureURL); err != nil {
+			return err
+		}
+	}
+
+	return j.db.Transaction(func(tx *gorm.DB) error {
+		return purgeUserData(tx, profile.ID)
+	})
+}
+
+// purgeUserData hard-deletes every row owned by the user, bypassing soft deletes
+func purgeUserData(tx *gorm.DB, userID uuid.UUID) error {
+	// The audit trail of the user's own data and of their actions holds personal data too
+	entries := tx.Session(&gorm.Session{NewDB: true}).Unscoped().Model(&models.TimeEntry{}).Select("id").Where("user_id = ?", userID)
+	projects := tx.Session(&gorm.Session{NewDB: true}).Unscoped().Model(&models.Project{}).Select("id").Where("user_id = ? AND workspace_id IS NULL", userID)
+	if err := tx.Where("actor_id = ? OR entity_id = ? OR entity_id IN (?) OR entity_id IN (?)", userID, userID, entries, projects).
+		Delete(&models.AuditLog{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.TimeEntry{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.Goal{}).Error; err != nil {
+		return err
+	}
+
+	timesheets := tx.Session(&gorm.Session{NewDB: true}).Model(&models.Timesheet{}).Select("id").Where("user_id = ?", userID)
+	if err := tx.Where("timesheet_id IN (?)", timesheets).Delete(&models.TimesheetEvent{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.Timesheet{}).Error; err != nil {
+		return err
+	}
+
+	// Shared projects belong to their workspace and stay
+	if err := tx.Unscoped().Where("user_id = ? AND workspace_id IS NULL", userID).Delete(&models.Project{}).Error; err != nil {
+		return err
+	}
+
+	if err := transferOwnedWorkspaces(tx, userID); err != nil {
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.PersonalAccessToken{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.DailyUserTotal{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserAchievement{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("requester_id = ? OR addressee_id = ?", userID, userID).Delete(&models.Friendship{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Where("id = ?", userID).Delete(&models.Profile{}).Error
+}
+
+// transferOwnedWorkspaces hands every workspace the user owns to its most privileged,
+// longest-standing other member so the workspace keeps an owner
+// Workspaces without another member would be left unmanageable, so they are deleted unless that changes locked time
+func transferOwnedWorkspaces(tx *gorm.DB, userID uuid.UUID) error {
+	var owned []models.WorkspaceMember
+	if err := tx.Where("user_id = ? AND role = ?", userID, models.WorkspaceRoleOwner).Find(&owned).Error; err != nil {
+		return err
+	}
+
+	for _, ownership := range owned {
+		var successor models.WorkspaceMember
+		err := tx.Where("workspace_id = ? AND user_id <> ?", ownership.WorkspaceID, userID).
+			Order(clause.Expr{SQL: "CASE role WHEN ? THEN 0 WHEN ? THEN 1 ELSE 2 END, created_at", Vars: []interface{}{models.WorkspaceRoleAdmin, models.WorkspaceRoleMember}}).
+			First(&successor).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := deleteWorkspace(tx, ownership.WorkspaceID); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.WorkspaceMember{}).
+			Where("workspace_id = ? AND user_id = ?", successor.WorkspaceID, successor.UserID).
+			Update("role", models.WorkspaceRoleOwner).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteWorkspace hard-deletes a workspace with its shared projects and their goals, its invitations and members,
+// auditing every change to the projects and time entries
+// Time former members tracked on the projects moves to no project. Entries in locked periods or approved
+// timesheets cannot change, so a workspace with such time on its projects is kept
+func deleteWorkspace(tx *gorm.DB, workspaceID uuid.UUID) error {
+	var projects []models.Project
+	if err := tx.Unscoped().Where("workspace_id = ?", workspaceID).Find(&projects).Error; err != nil {
+		return err
+	}
+	projectIDs := make([]uuid.UUID, 0, len(projects))
+	for _, project := range projects {
+		projectIDs = append(projectIDs, project.ID)
+	}
+
+	var affectedEntries []models.TimeEntry
+	if err := tx.Unscoped().Where("project_id IN ?", projectIDs).Find(&affectedEntries).Error; err != nil {
+		return err
+	}
+	for _, entry := range affectedEntries {
+		locked, err := periodlocks.Locked(tx, entry.UserID, entry.StartTime)
+		if err == nil && !locked {
+			locked, err = timesheets.Locked(tx, entry)
+		}
+		if err != nil {
+			return err
+		}
+		if locked {
+			log.Printf("Keeping workspace %s: its projects have locked time entries", workspaceID)
+			return nil
+		}
+	}
+
+	// The purge runs without a request, so its changes are audited without an actor
+	auditCtx := audit.Context{}
+
+	if err := tx.Unscoped().Model(&models.TimeEntry{}).Where("project_id IN ?", projectIDs).Update("project_id", nil).Error; err != nil {
+		return err
+	}
+	affectedUserIDs := map[uuid.UUID]bool{}
+	for _, entry := range affectedEntries {
+		affectedUserIDs[entry.UserID] = true
+		updated := entry
+		updated.ProjectID = nil
+		if err := audit.Record(tx, auditCtx, models.AuditEntityTimeEntry, entry.ID, entry, updated); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Where("project_id IN ?", projectIDs).Delete(&models.Goal{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Where("workspace_id = ?", workspaceID).Delete(&models.Project{}).Error; err != nil {
+		return err
+	}
+	for _, project := range projects {
+		if err := audit.Record(tx, auditCtx, models.AuditEntityProject, project.ID, project, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.WorkspaceInvitation{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("id = ?", workspaceID).Delete(&models.Workspace{}).Error; err != nil {
+		return err
+	}
+
+	// Move the projects' time to "no project" in the daily totals
+	for affectedUserID := range affectedUserIDs {
+		if err := stats.RebuildUser(tx, affectedUserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+	"time-tracker/models"
+	"time-tracker/stats"
+
+	"gorm.io/gorm"
+)
+
+// statsRebuildRunning is set while a full statistics rebuild runs
+var statsRebuildRunning atomic.Bool
+
+// StartStatsRebuild rebuilds every user's daily totals in the background
+// Returns false without starting anything if a rebuild is already running
+func StartStatsRebuild(db *gorm.DB) bool {
+	if !statsRebuildRunning.CompareAndSwap(false, true) {
+		return false
+	}
+
+	go func() {
+		defer statsRebuildRunning.Store(false)
+
+		started := time.Now()
+		if err := stats.RebuildAll(db); err != nil {
+			log.Printf("Failed to recalculate statistics: %v", err)
+			return
+		}
+		log.Printf("Recalculated statistics in %s", time.Since(started))
+	}()
+	return true
+}
+
+// StatsRebuildRunning reports whether a full statistics rebuild is in progress
+func StatsRebuildRunning() bool {
+	return statsRebuildRunning.Load()
+}
+
+// BackfillStats starts a full statistics rebuild when time was tracked but the daily totals are empty,
+// as after the daily totals table was first created, so existing users do not show zero hours
+func BackfillStats(db *gorm.DB) error {
+	var totals []models.DailyUserTotal
+	if err := db.Limit(1).Find(&totals).Error; err != nil || len(totals) > 0 {
+		return err
+	}
+	var entries []models.TimeEntry
+	if err := db.Limit(1).Find(&entries).Error; err != nil || len(entries) == 0 {
+		return err
+	}
+
+	log.Println("Daily totals are empty, recalculating statistics from time entries")
+	StartStatsRebuild(db)
+	return nil
+}
@@ -0,0 +1,118 @@
+package levels
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+	"time-tracker/models"
+
+	"gorm.io/gorm"
+)
+
+// cacheTTL bounds how long tiers changed through another instance stay stale,
+// since Invalidate only clears the cache of the process it runs in
+const cacheTTL = time.Minute
+
+var (
+	mu       sync.RWMutex
+	tiers    []models.LevelTier // sorted by MinHours, nil until loaded
+	loadedAt time.Time
+)
+
+// Load reads the level tiers from the database into the cache and returns them
+// Falls back to the default tiers if the table is empty or cannot be read
+func Load(db *gorm.DB) []models.LevelTier {
+	var loaded []models.LevelTier
+	if err := db.Order("min_hours").Find(&loaded).Error; err != nil {
+		log.Printf("Failed to load level tiers, using defaults: %v", err)
+		loaded = nil
+	}
+	if len(loaded) == 0 {
+		loaded = append([]models.LevelTier(nil), models.DefaultLevelTiers...)
+	}
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].MinHours < loaded[j].MinHours })
+
+	mu.Lock()
+	tiers = loaded
+	loadedAt = time.Now()
+	mu.Unlock()
+	return loaded
+}
+
+// Invalidate drops the cache so the next lookup reloads the tiers
+// Call it after the level tiers table changes
+func Invalidate() {
+	mu.Lock()
+	tiers = nil
+	mu.Unlock()
+}
+
+// cached returns the cached tiers, loading them on first use and once they expire
+func cached(db *gorm.DB) []models.LevelTier {
+	mu.RLock()
+	current := tiers
+	expired := time.Since(loadedAt) > cacheTTL
+	mu.RUnlock()
+
+	// Use the tiers just loaded: an Invalidate in the meantime clears the cache again
+	if current == nil || expired {
+		return Load(db)
+	}
+	return current
+}
+
+// Tiers returns the level tiers sorted by their thresholds, loading them when needed
+func Tiers(db *gorm.DB) []models.LevelTier {
+	return cached(db)
+}
+
+// ForHours returns the level reached with the given total hours and the progress towards the next one
+func ForHours(db *gorm.DB, hours float32) models.LevelProgress {
+	return Progress(cached(db), hours)
+}
+
+// Progress returns the level reached with the given total hours among tiers sorted by threshold
+// Tiers never returns an empty list since the defaults stand in for an empty table,
+// but an empty list gives the zero progress rather than a panic
+func Progress(all []models.LevelTier, hours float32) models.LevelProgress {
+	if len(all) == 0 {
+		return models.LevelProgress{}
+	}
+
+	// The lowest tier applies even below its threshold
+	current := 0
+	for i, tier := range all {
+		if float64(hours) >= tier.MinHours {
+			current = i
+		}
+	}
+
+	tier := all[current]
+	progress := models.LevelProgress{
+		Level:         tier.Name,
+		LevelColor:    tier.Color,
+		LevelIcon:     tier.Icon,
+		LevelProgress: 100,
+	}
+
+	if current+1 < len(all) {
+		next := all[current+1]
+		span := next.MinHours - tier.MinHours
+		done := float64(hours) - tier.MinHours
+		if done < 0 {
+			done = 0
+		}
+
+		progress.NextLevel = &next.Name
+		progress.HoursToNextLevel = round1(float32(next.MinHours - float64(hours)))
+		progress.LevelProgress = round1(float32(done / span * 100))
+	}
+
+	return progress
+}
+
+// round1 rounds to one decimal place
+func round1(value float32) float32 {
+	return float32(int(value*10+0.5)) / 10
+}
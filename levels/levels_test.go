@@ -0,0 +1,24 @@
+package levels
+
+import (
+	"testing"
+	"time-tracker/models"
+)
+
+func TestProgressWithoutTiers(t *testing.T) {
+	if got := Progress(nil, 12); got != (models.LevelProgress{}) {
+		t.Fatalf("expected no level without tiers, got %+v", got)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	tiers := []models.LevelTier{{Name: "Rookie", MinHours: 0}, {Name: "Pro", MinHours: 10}}
+
+	got := Progress(tiers, 5)
+	if got.Level != "Rookie" || got.NextLevel == nil || *got.NextLevel != "Pro" || got.HoursToNextLevel != 5 || got.LevelProgress != 50 {
+		t.Fatalf("unexpected progress %+v", got)
+	}
+	if got := Progress(tiers, 15); got.Level != "Pro" || got.NextLevel != nil || got.LevelProgress != 100 {
+		t.Fatalf("unexpected progress at the highest level %+v", got)
+	}
+}
@@ -1,44 +1,82 @@
 package main
 
 import (
+	"context"
 	"log"
-	"os"
+	"net/http"
+	"time"
+	"time-tracker/apispec"
+	"time-tracker/config"
 	"time-tracker/database"
+	"time-tracker/jobs"
+	"time-tracker/middleware"
 	"time-tracker/routes"
 	"time-tracker/supabase"
+	_ "time/tzdata" // Embed the timezone database for profile timezones
 
-	"github.com/joho/godotenv"
+	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// Load environment variables
-	err := godotenv.Load()
+	// Load and validate settings from the environment, .env and the optional CONFIG_FILE
+	cfg, err := config.Load()
 	if err != nil {
-		log.Println("Warning: .env file not found, using system environment variables")
+		log.Fatal("Invalid configuration:\n", err)
 	}
 
-	// Initialize Supabase client
-	supabase.InitClient()
+	if cfg.Server.GinMode != "" {
+		gin.SetMode(cfg.Server.GinMode)
+	}
 
+	// Supabase client, used for profile picture storage
+	storage := supabase.NewClient(cfg.Supabase, cfg.Storage)
 
 	// Connect to database
-	database.Connect()
+	db := database.Connect(cfg.Database)
 
 	// Run migrations
-	database.Migrate()
+	database.Migrate(db)
+
+	// Fill the daily totals statistics are read from if they were never built
+	if err := jobs.BackfillStats(db); err != nil {
+		log.Fatal("Failed to check statistics:", err)
+	}
+
+	// Purge accounts whose deletion grace period has expired
+	jobs.NewAccountDeletion(db, storage).Start(time.Hour)
+
+	// Verify access tokens, loading JWKS keys in the background
+	verifier := middleware.NewVerifier(cfg.Auth.JWT)
+	verifier.Start(context.Background())
+
+	// Load the API specification requests and responses are validated against
+	var spec *apispec.Spec
+	if cfg.OpenAPI.Mode != config.OpenAPIModeOff {
+		spec, err = apispec.Load(cfg.OpenAPI.SpecPath)
+		if err != nil {
+			log.Fatal("Failed to load API specification (set OPENAPI_VALIDATION=off to run without it): ", err)
+		}
+	}
 
 	// Setup routes
-	r := routes.SetupRoutes()
+	r := routes.SetupRoutes(cfg, routes.Dependencies{
+		DB:       db,
+		Storage:  storage,
+		Verifier: verifier,
+		Spec:     spec,
+	})
 
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	server := &http.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      r,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	// Start server
-	log.Printf("Starting server on :%s", port)
-	if err := r.Run(":" + port); err != nil {
+	log.Printf("Starting server on :%s", cfg.Server.Port)
+	if err := server.ListenAndServe(); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
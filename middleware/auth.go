@@ -1,16 +1,23 @@
 package middleware
 
 import (
-	"log"
+	"errors"
 	"net/http"
-	"os"
 	"strings"
+	"time-tracker/models"
+	"time-tracker/tokens"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+// How a request was authenticated, stored as auth_method in the context
+const (
+	AuthMethodJWT                 = "jwt"
+	AuthMethodPersonalAccessToken = "personal_access_token"
+)
+
 type Claims struct {
 	Sub   string `json:"sub"`
 	Email string `json:"email"`
@@ -18,14 +25,14 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-// SupabaseAuth middleware - simple JWT validation
-func SupabaseAuth() gin.HandlerFunc {
+// SupabaseAuth middleware - JWT validation with JWT_SECRET (HS256) and/or JWT_JWKS_URL (RS256/ES256)
+// Tokens are only accepted unverified with AUTH_MODE=insecure-dev
+func (a *Authenticator) SupabaseAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			log.Println("HERE", authHeader)
 			c.Abort()
 			return
 		}
@@ -41,20 +48,30 @@ func SupabaseAuth() gin.HandlerFunc {
 
 		tokenString := tokenParts[1]
 
-		// Simple approach: Use JWT secret for verification
-		jwtSecret := os.Getenv("JWT_SECRET")
-		if jwtSecret == "" {
-			// Fallback to development mode - parse without verification
-			token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &Claims{})
+		// Personal access tokens act as their user, limited to the token's scopes
+		if tokens.IsPersonalAccessToken(tokenString) {
+			pat, err := tokens.Authenticate(a.db, tokenString)
+			if errors.Is(err, tokens.ErrInvalidToken) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+				c.Abort()
+				return
+			}
 			if err != nil {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify token"})
 				c.Abort()
 				return
 			}
 
-			claims, ok := token.Claims.(*Claims)
-			if !ok {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			setPersonalAccessToken(c, pat)
+			c.Next()
+			return
+		}
+
+		if a.InsecureDev() {
+			// Development mode (AUTH_MODE=insecure-dev) - only development tokens are accepted
+			claims, err := parseDevToken(tokenString)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 				c.Abort()
 				return
 			}
@@ -70,28 +87,19 @@ func SupabaseAuth() gin.HandlerFunc {
 			c.Set("user_email", claims.Email)
 			c.Set("user_role", claims.Role)
 			c.Set("access_token", tokenString)
+			c.Set("auth_method", AuthMethodJWT)
 			c.Next()
 			return
 		}
 
-		// Production: verify with JWT secret
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
+		// Production: verify the signature and claims
+		claims, err := a.verifier.Verify(c.Request.Context(), tokenString)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(*Claims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
-			return
-		}
-
 		userID, err := uuid.Parse(claims.Sub)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
@@ -104,13 +112,14 @@ func SupabaseAuth() gin.HandlerFunc {
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
 		c.Set("access_token", tokenString)
+		c.Set("auth_method", AuthMethodJWT)
 
 		c.Next()
 	}
 }
 
 // OptionalAuth middleware - simple version
-func OptionalAuth() gin.HandlerFunc {
+func (a *Authenticator) OptionalAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -125,33 +134,33 @@ func OptionalAuth() gin.HandlerFunc {
 		}
 
 		tokenString := tokenParts[1]
-		jwtSecret := os.Getenv("JWT_SECRET")
-
-		if jwtSecret == "" {
-			// Development mode: parse without verification
-			token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &Claims{})
-			if err == nil {
-				if claims, ok := token.Claims.(*Claims); ok {
-					if userID, err := uuid.Parse(claims.Sub); err == nil {
-						c.Set("user_id", userID)
-						c.Set("user_email", claims.Email)
-						c.Set("user_role", claims.Role)
-					}
+
+		if tokens.IsPersonalAccessToken(tokenString) {
+			if pat, err := tokens.Authenticate(a.db, tokenString); err == nil {
+				setPersonalAccessToken(c, pat)
+			}
+			c.Next()
+			return
+		}
+
+		if a.InsecureDev() {
+			// Development mode (AUTH_MODE=insecure-dev): only development tokens are accepted
+			if claims, err := parseDevToken(tokenString); err == nil {
+				if userID, err := uuid.Parse(claims.Sub); err == nil {
+					c.Set("user_id", userID)
+					c.Set("user_email", claims.Email)
+					c.Set("user_role", claims.Role)
+					c.Set("auth_method", AuthMethodJWT)
 				}
 			}
 		} else {
-			// Production: verify with secret
-			token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-				return []byte(jwtSecret), nil
-			})
-
-			if err == nil && token.Valid {
-				if claims, ok := token.Claims.(*Claims); ok {
-					if userID, err := uuid.Parse(claims.Sub); err == nil {
-						c.Set("user_id", userID)
-						c.Set("user_email", claims.Email)
-						c.Set("user_role", claims.Role)
-					}
+			// Production: verify the signature and claims
+			if claims, err := a.verifier.Verify(c.Request.Context(), tokenString); err == nil {
+				if userID, err := uuid.Parse(claims.Sub); err == nil {
+					c.Set("user_id", userID)
+					c.Set("user_email", claims.Email)
+					c.Set("user_role", claims.Role)
+					c.Set("auth_method", AuthMethodJWT)
 				}
 			}
 		}
@@ -159,3 +168,12 @@ func OptionalAuth() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// setPersonalAccessToken stores the token's user and scopes in the context
+// Token requests carry no JWT role, so role-protected routes stay out of reach
+func setPersonalAccessToken(c *gin.Context, pat models.PersonalAccessToken) {
+	c.Set("user_id", pat.UserID)
+	c.Set("token_id", pat.ID)
+	c.Set("token_scopes", tokens.Scopes(pat))
+	c.Set("auth_method", AuthMethodPersonalAccessToken)
+}
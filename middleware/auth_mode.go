@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"time"
+	"time-tracker/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Authentication modes, selected with AUTH_MODE
+const (
+	AuthModeVerify      = config.AuthModeVerify      // Tokens must be signed with a configured key (default)
+	AuthModeInsecureDev = config.AuthModeInsecureDev // Only development tokens are accepted, for local development only
+)
+
+// insecureDevSecret signs development tokens, and is the only key insecure development mode accepts
+// It is public, so it must never sign tokens another environment would accept
+const insecureDevSecret = "insecure-dev"
+
+// Authenticator holds what the auth middleware needs: the settings, the token verifier
+// and the database personal access tokens are looked up in
+type Authenticator struct {
+	config   config.Auth
+	verifier *Verifier
+	db       *gorm.DB
+}
+
+// NewAuthenticator returns the auth middleware for the settings, checking signatures with verifier
+func NewAuthenticator(cfg config.Auth, verifier *Verifier, db *gorm.DB) *Authenticator {
+	if cfg.Mode == AuthModeInsecureDev {
+		log.Println("==================================================================")
+		log.Println("WARNING: AUTH_MODE=insecure-dev - only development tokens are accepted.")
+		log.Println("WARNING: Anyone can sign in as any user. Never use this in production.")
+		log.Println("==================================================================")
+	}
+
+	return &Authenticator{config: cfg, verifier: verifier, db: db}
+}
+
+// Mode returns the configured authentication mode
+func (a *Authenticator) Mode() string {
+	if a.config.Mode != "" {
+		return a.config.Mode
+	}
+	return AuthModeVerify
+}
+
+// InsecureDev reports whether development tokens are accepted instead of real ones
+func (a *Authenticator) InsecureDev() bool {
+	return a.Mode() == AuthModeInsecureDev
+}
+
+// NewDevToken issues a token for the user that is valid for ttl, signed with the development key
+// Only insecure development mode accepts these tokens
+func (a *Authenticator) NewDevToken(userID uuid.UUID, email, role string, ttl time.Duration) (string, time.Time, error) {
+	if !a.InsecureDev() {
+		return "", time.Time{}, errors.New("development tokens require AUTH_MODE=insecure-dev")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		Sub:   userID.String(),
+		Email: email,
+		Role:  role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{a.config.JWT.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	signed, err := token.SignedString([]byte(insecureDevSecret))
+	return signed, expiresAt, err
+}
+
+// parseDevToken returns the claims of a development token
+// Tokens signed with any other key, including real ones, are rejected
+func parseDevToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(insecureDevSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	return claims, err
+}
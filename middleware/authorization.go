@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// forbidden aborts the request with the standard 403 response
+func forbidden(c *gin.Context, message string) {
+	c.JSON(http.StatusForbidden, gin.H{"error": message})
+	c.Abort()
+}
+
+// AdminOnly middleware - requires the authenticated user to have one of the configured admin roles
+// Must run after SupabaseAuth
+func (a *Authenticator) AdminOnly() gin.HandlerFunc {
+	return RequireRole(a.config.AdminRoles...)
+}
+
+// RequireRole middleware - requires the authenticated user's JWT role to be one of roles
+// Must run after SupabaseAuth. Personal access tokens carry no role and are always rejected
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("user_role")
+		for _, allowed := range roles {
+			if role != "" && role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		forbidden(c, "Insufficient role")
+	}
+}
+
+// HasScope reports whether the request may act with the scope
+// JWT sessions act with the user's full access; personal access tokens only with their scopes
+func HasScope(c *gin.Context, scope string) bool {
+	if c.GetString("auth_method") != AuthMethodPersonalAccessToken {
+		return true
+	}
+	for _, granted := range c.GetStringSlice("token_scopes") {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScope middleware - requires personal access tokens to carry the scope
+// Must run after SupabaseAuth
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasScope(c, scope) {
+			forbidden(c, "Token is missing the "+scope+" scope")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireResourceScope middleware - requires the resource's read scope for GET and HEAD
+// requests and its write scope for everything else, e.g. time_entries:read and time_entries:write
+// Must run after SupabaseAuth
+func RequireResourceScope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := resource + ":write"
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = resource + ":read"
+		}
+
+		if !HasScope(c, scope) {
+			forbidden(c, "Token is missing the "+scope+" scope")
+			return
+		}
+		c.Next()
+	}
+}
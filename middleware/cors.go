@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time-tracker/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// originPattern matches an allowed origin, with an optional wildcard subdomain
+type originPattern struct {
+	prefix string // Whole origin, or the scheme up to the wildcard
+	suffix string // Domain after the wildcard, e.g. ".example.com"
+	any    bool
+	exact  bool
+}
+
+func newOriginPattern(origin string) originPattern {
+	origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+	if origin == "*" {
+		return originPattern{any: true}
+	}
+	if prefix, suffix, ok := strings.Cut(origin, "*"); ok {
+		return originPattern{prefix: prefix, suffix: suffix}
+	}
+	return originPattern{prefix: origin, exact: true}
+}
+
+func (p originPattern) matches(origin string) bool {
+	switch {
+	case p.any:
+		return true
+	case p.exact:
+		return origin == p.prefix
+	}
+	if len(origin) <= len(p.prefix)+len(p.suffix) {
+		return false
+	}
+	if !strings.HasPrefix(origin, p.prefix) || !strings.HasSuffix(origin, p.suffix) {
+		return false
+	}
+	// The wildcard stands for subdomain labels only, not paths, ports or credentials
+	subdomain := origin[len(p.prefix) : len(origin)-len(p.suffix)]
+	return !strings.ContainsAny(subdomain, "/:@")
+}
+
+// CORS middleware - answers preflight requests and adds CORS headers for allowed origins
+// Responses always vary by Origin, so caches do not serve one origin's headers to another
+// Any-origin policies answer with a literal *, which browsers never combine with credentials
+func CORS(cfg config.CORS) gin.HandlerFunc {
+	patterns := make([]originPattern, 0, len(cfg.AllowedOrigins))
+	anyOrigin := false
+	for _, origin := range cfg.AllowedOrigins {
+		pattern := newOriginPattern(origin)
+		anyOrigin = anyOrigin || pattern.any
+		patterns = append(patterns, pattern)
+	}
+
+	allowMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		origin := c.GetHeader("Origin")
+		allowed := false
+		if origin != "" {
+			normalized := strings.ToLower(origin)
+			for _, pattern := range patterns {
+				if pattern.matches(normalized) {
+					allowed = true
+					break
+				}
+			}
+		}
+
+		if allowed {
+			if anyOrigin {
+				c.Header("Access-Control-Allow-Origin", "*")
+			} else {
+				c.Header("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+			if exposeHeaders != "" {
+				c.Header("Access-Control-Expose-Headers", exposeHeaders)
+			}
+		}
+
+		if preflight {
+			if allowed {
+				c.Header("Access-Control-Allow-Methods", allowMethods)
+				c.Header("Access-Control-Allow-Headers", allowHeaders)
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwksMinRefreshInterval limits refreshes triggered by unknown key IDs,
+// so tokens with made-up kids cannot make us hammer the JWKS endpoint
+const jwksMinRefreshInterval = 30 * time.Second
+
+// ErrUnknownKey is returned for key IDs the JWKS endpoint does not publish
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// jsonWebKey is a public key as published in a JWKS document
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKS caches the signing keys published at a JWKS endpoint by key ID
+// Keys are refreshed in the background and on demand when a token names an unknown key,
+// so rotated keys are picked up without a restart
+type JWKS struct {
+	url    string
+	client *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]interface{}
+	lastRefresh time.Time
+	refreshing  sync.Mutex
+}
+
+// NewJWKS returns a key cache for the JWKS endpoint at url; call Refresh or StartRefresh to load it
+func NewJWKS(url string) *JWKS {
+	return &JWKS{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   map[string]interface{}{},
+	}
+}
+
+// Refresh replaces the cached keys with the ones currently published
+// Keys of unsupported types are skipped
+func (j *JWKS) Refresh(ctx context.Context) error {
+	j.refreshing.Lock()
+	defer j.refreshing.Unlock()
+	return j.refresh(ctx)
+}
+
+// refresh fetches the keys; the caller holds j.refreshing
+// The attempt counts towards the cooldown even if it fails, so an unreachable endpoint is not hammered either
+func (j *JWKS) refresh(ctx context.Context) error {
+	j.mu.Lock()
+	j.lastRefresh = time.Now()
+	j.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return fmt.Errorf("decode JWKS: %w", err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Printf("Skipping JWKS key %q: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+	return nil
+}
+
+// StartRefresh loads the keys and keeps refreshing them every interval until ctx is done
+// Failed refreshes are logged and the previous keys stay in use
+func (j *JWKS) StartRefresh(ctx context.Context, interval time.Duration) {
+	if err := j.Refresh(ctx); err != nil {
+		log.Printf("Failed to load JWKS from %s: %v", j.url, err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := j.Refresh(ctx); err != nil {
+					log.Printf("Failed to refresh JWKS from %s: %v", j.url, err)
+				}
+			}
+		}
+	}()
+}
+
+// Key returns the public key with the given ID, refreshing the cache once if it is unknown
+func (j *JWKS) Key(ctx context.Context, kid string) (interface{}, error) {
+	if key, ok := j.cached(kid); ok {
+		return key, nil
+	}
+
+	// Concurrent requests for unknown keys wait here, so only the first one fetches
+	j.refreshing.Lock()
+	defer j.refreshing.Unlock()
+	if key, ok := j.cached(kid); ok {
+		return key, nil
+	}
+
+	j.mu.RLock()
+	stale := time.Since(j.lastRefresh) >= jwksMinRefreshInterval
+	j.mu.RUnlock()
+	if !stale {
+		return nil, ErrUnknownKey
+	}
+	if err := j.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if key, ok := j.cached(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func (j *JWKS) cached(kid string) (interface{}, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+// publicKey decodes an RSA or P-256 EC key
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != 32 {
+			return nil, errors.New("invalid x coordinate")
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil || len(y) != 32 {
+			return nil, errors.New("invalid y coordinate")
+		}
+		// Reject points that are not on the curve
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, errors.New("invalid curve point")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jwksServer publishes a JWKS document that tests can rotate, counting how often it is fetched
+type jwksServer struct {
+	*httptest.Server
+	fetches atomic.Int32
+
+	mu   sync.Mutex
+	keys []jsonWebKey
+}
+
+func newJWKSServer(t *testing.T, keys ...jsonWebKey) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string][]jsonWebKey{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// publish replaces the published keys
+func (s *jwksServer) publish(keys ...jsonWebKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	return key
+}
+
+func newECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate EC key: %v", err)
+	}
+	return key
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "EC",
+		Kid: kid,
+		Use: "sig",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// expire lets the next unknown key ID trigger a refresh again
+func (j *JWKS) expire() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.lastRefresh = time.Now().Add(-jwksMinRefreshInterval)
+}
+
+func TestJWKSKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	oldKey := newRSAKey(t)
+	newKey := newECKey(t)
+	server := newJWKSServer(t, rsaJWK("old", &oldKey.PublicKey))
+
+	jwks := NewJWKS(server.URL)
+	if err := jwks.Refresh(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if _, err := jwks.Key(ctx, "old"); err != nil {
+		t.Fatalf("expected the published key, got %v", err)
+	}
+
+	// Right after a refresh an unknown key ID does not fetch again
+	server.publish(rsaJWK("old", &oldKey.PublicKey), ecJWK("new", &newKey.PublicKey))
+	if _, err := jwks.Key(ctx, "new"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey during the cooldown, got %v", err)
+	}
+	if got := server.fetches.Load(); got != 1 {
+		t.Fatalf("expected one fetch during the cooldown, got %d", got)
+	}
+
+	// Once the cooldown passed the unknown key ID is looked up
+	jwks.expire()
+	key, err := jwks.Key(ctx, "new")
+	if err != nil {
+		t.Fatalf("expected the rotated key, got %v", err)
+	}
+	if !newKey.PublicKey.Equal(key) {
+		t.Fatal("expected the rotated key to match the published one")
+	}
+	if got := server.fetches.Load(); got != 2 {
+		t.Fatalf("expected a second fetch, got %d", got)
+	}
+
+	// Keys that are no longer published are dropped
+	server.publish(ecJWK("new", &newKey.PublicKey))
+	if err := jwks.Refresh(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if _, err := jwks.Key(ctx, "old"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected the retired key to be unknown, got %v", err)
+	}
+}
+
+func TestJWKSConcurrentUnknownKeys(t *testing.T) {
+	server := newJWKSServer(t)
+	jwks := NewJWKS(server.URL)
+	jwks.expire()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := jwks.Key(context.Background(), "made-up"); !errors.Is(err, ErrUnknownKey) {
+				t.Errorf("expected ErrUnknownKey, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := server.fetches.Load(); got != 1 {
+		t.Fatalf("expected concurrent lookups to share one fetch, got %d", got)
+	}
+}
+
+func TestJWKSUnreachable(t *testing.T) {
+	server := newJWKSServer(t)
+	server.Close()
+
+	jwks := NewJWKS(server.URL)
+	jwks.expire()
+	if _, err := jwks.Key(context.Background(), "any"); err == nil || errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected the fetch to fail, got %v", err)
+	}
+	// The failed attempt starts the cooldown too
+	if _, err := jwks.Key(context.Background(), "any"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey during the cooldown, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"time-tracker/apispec"
+	"time-tracker/config"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIValidation middleware - checks requests, and responses if enabled, against the API specification
+// In log mode violations are only logged. In strict mode invalid requests are rejected with a 400
+// and invalid responses are replaced with a 500, so tests fail on any drift from the spec.
+// Register it after authentication and rate limiting, so those answer first
+func OpenAPIValidation(spec *apispec.Spec, cfg config.OpenAPI) gin.HandlerFunc {
+	strict := cfg.Mode == config.OpenAPIModeStrict
+
+	return func(c *gin.Context) {
+		if spec == nil || cfg.Mode == config.OpenAPIModeOff || c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		route, ok := spec.Route(c.Request.Method, c.FullPath())
+		if !ok {
+			log.Printf("OpenAPI: %s %s is not documented", c.Request.Method, c.FullPath())
+			if strict {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Route is not documented in the API specification"})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		params := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = param.Value
+		}
+		request := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: params,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc, // Authentication is up to the auth middleware
+				SkipSettingDefaults: true,                                  // Handlers apply their own defaults
+			},
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), request); err != nil {
+			log.Printf("OpenAPI: invalid request to %s %s: %v", c.Request.Method, c.FullPath(), err)
+			if strict {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		if !cfg.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		writer := &contractWriter{ResponseWriter: c.Writer, hold: strict, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		response := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: request,
+			Status:                 writer.status,
+			Header:                 writer.Header(),
+			Options: &openapi3filter.Options{
+				IncludeResponseStatus: true, // Undocumented status codes are violations too
+			},
+		}
+		response.SetBodyBytes(writer.body.Bytes())
+		err := openapi3filter.ValidateResponse(c.Request.Context(), response)
+		if err != nil {
+			log.Printf("OpenAPI: invalid %d response from %s %s: %v", writer.status, c.Request.Method, c.FullPath(), err)
+		}
+
+		if !strict {
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Response does not match the API specification: " + err.Error()})
+			return
+		}
+		writer.flush()
+	}
+}
+
+// contractWriter keeps a copy of the response body so it can be validated
+// With hold set the response is only written by flush, once it has been validated
+type contractWriter struct {
+	gin.ResponseWriter
+	hold    bool
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *contractWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+	if !w.hold {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *contractWriter) WriteHeaderNow() {
+	w.written = true
+	if !w.hold {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *contractWriter) Write(data []byte) (int, error) {
+	w.written = true
+	w.body.Write(data)
+	if w.hold {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *contractWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *contractWriter) Status() int {
+	return w.status
+}
+
+func (w *contractWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *contractWriter) Written() bool {
+	return w.written
+}
+
+// flush writes a held response
+func (w *contractWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.ResponseWriter.Write(w.body.Bytes())
+}
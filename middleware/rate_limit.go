@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"time-tracker/config"
+	"time-tracker/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RateLimiter throttles requests with token buckets kept in a store
+type RateLimiter struct {
+	store  ratelimit.Store
+	config config.RateLimit
+}
+
+// NewRateLimiter returns a rate limiter using the store and limits
+func NewRateLimiter(store ratelimit.Store, cfg config.RateLimit) *RateLimiter {
+	return &RateLimiter{store: store, config: cfg}
+}
+
+// Limit middleware - throttles requests to a route group with the group's own buckets
+// Requests are counted per user after SupabaseAuth and per client IP before it.
+// Sets RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset, and Retry-After on 429 responses
+func (l *RateLimiter) Limit(group string) gin.HandlerFunc {
+	limit := l.config.Limit(group)
+	policy := fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Period.Seconds()))
+
+	return func(c *gin.Context) {
+		if !limit.Enabled() {
+			c.Next()
+			return
+		}
+
+		key := group + ":ip:" + c.ClientIP()
+		if userID, exists := c.Get("user_id"); exists {
+			key = group + ":user:" + userID.(uuid.UUID).String()
+		}
+
+		result, err := l.store.Take(c.Request.Context(), key, limit, time.Now())
+		if err != nil {
+			// Fail open: an unavailable store must not take the API down
+			log.Printf("Rate limit store error: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
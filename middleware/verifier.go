@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time-tracker/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Verifier checks access token signatures and claims
+// Only the algorithms backed by configured keys are accepted: HS256 with a secret,
+// RS256 and ES256 with a JWKS endpoint
+type Verifier struct {
+	config  config.JWT
+	jwks    *JWKS
+	methods []string
+}
+
+// NewVerifier returns a verifier for the configuration; call Start to load JWKS keys
+func NewVerifier(cfg config.JWT) *Verifier {
+	v := &Verifier{config: cfg}
+	if cfg.Secret != "" {
+		v.methods = append(v.methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.JWKSURL != "" {
+		v.jwks = NewJWKS(cfg.JWKSURL)
+		v.methods = append(v.methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+	return v
+}
+
+// Start loads the JWKS keys and keeps them fresh until ctx is done
+func (v *Verifier) Start(ctx context.Context) {
+	if v.jwks != nil {
+		log.Printf("Verifying RS256/ES256 tokens with keys from %s", v.config.JWKSURL)
+		v.jwks.StartRefresh(ctx, v.config.JWKSRefreshInterval)
+	}
+}
+
+// Enabled reports whether any verification key is configured
+func (v *Verifier) Enabled() bool {
+	return len(v.methods) > 0
+}
+
+// Verify checks the token's algorithm, signature, issuer, audience and validity period
+// and returns its claims
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	if !v.Enabled() {
+		return nil, errors.New("no token verification keys configured")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(v.methods),
+		jwt.WithLeeway(v.config.ClockSkew),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithAudience(v.config.Audience),
+	}
+	if v.config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(v.config.Issuer))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			return []byte(v.config.Secret), nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+			kid, _ := token.Header["kid"].(string)
+			return v.jwks.Key(ctx, kid)
+		}
+		return nil, jwt.ErrTokenUnverifiable
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
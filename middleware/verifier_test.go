@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+	"time-tracker/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	testIssuer   = "https://project.supabase.co/auth/v1"
+	testAudience = "authenticated"
+)
+
+// newTestVerifier returns a verifier for tokens signed with keys from the JWKS server
+func newTestVerifier(t *testing.T, server *jwksServer, secret string) *Verifier {
+	t.Helper()
+
+	v := NewVerifier(config.JWT{
+		Secret:              secret,
+		JWKSURL:             server.URL,
+		Issuer:              testIssuer,
+		Audience:            testAudience,
+		ClockSkew:           30 * time.Second,
+		JWKSRefreshInterval: time.Hour,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	v.Start(ctx)
+	return v
+}
+
+// validClaims returns claims the test verifier accepts
+func validClaims() Claims {
+	now := time.Now()
+	return Claims{
+		Sub:  uuid.NewString(),
+		Role: "authenticated",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			Audience:  jwt.ClaimStrings{testAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func TestVerifierAlgorithms(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	ecKey := newECKey(t)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey), ecJWK("ec", &ecKey.PublicKey))
+	v := newTestVerifier(t, server, "")
+	ctx := context.Background()
+
+	claims := validClaims()
+	for name, token := range map[string]string{
+		"RS256": sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", claims),
+		"ES256": sign(t, jwt.SigningMethodES256, ecKey, "ec", claims),
+	} {
+		got, err := v.Verify(ctx, token)
+		if err != nil {
+			t.Fatalf("expected the %s token to be accepted, got %v", name, err)
+		}
+		if got.Sub != claims.Sub {
+			t.Fatalf("expected subject %s, got %s", claims.Sub, got.Sub)
+		}
+	}
+
+	// A key published under another kid does not verify the token
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodRS256, rsaKey, "ec", claims)); err == nil {
+		t.Fatal("expected a token naming the wrong key to be rejected")
+	}
+}
+
+func TestVerifierRejectsForgedAlgorithms(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey))
+	ctx := context.Background()
+
+	unsigned := sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "rsa", validClaims())
+
+	// The classic confusion attack: an HS256 token whose secret is the published public key
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	confused := sign(t, jwt.SigningMethodHS256, publicKeyPEM, "rsa", validClaims())
+
+	// With and without an HS256 secret configured
+	for _, secret := range []string{"", "shared-secret"} {
+		v := newTestVerifier(t, server, secret)
+		if _, err := v.Verify(ctx, unsigned); err == nil {
+			t.Fatalf("expected alg none to be rejected (secret %q)", secret)
+		}
+		if _, err := v.Verify(ctx, confused); err == nil {
+			t.Fatalf("expected HS256 signed with the public key to be rejected (secret %q)", secret)
+		}
+	}
+}
+
+func TestVerifierClaims(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey))
+	v := newTestVerifier(t, server, "")
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		modify func(*Claims)
+		valid  bool
+	}{
+		{"valid", func(c *Claims) {}, true},
+		{"wrong issuer", func(c *Claims) { c.Issuer = "https://attacker.example.com" }, false},
+		{"missing issuer", func(c *Claims) { c.Issuer = "" }, false},
+		{"wrong audience", func(c *Claims) { c.Audience = jwt.ClaimStrings{"anon"} }, false},
+		{"missing expiry", func(c *Claims) { c.ExpiresAt = nil }, false},
+		{"expired", func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute)) }, false},
+		{"expired within the leeway", func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-10 * time.Second)) }, true},
+		{"not yet valid", func(c *Claims) { c.NotBefore = jwt.NewNumericDate(now.Add(time.Minute)) }, false},
+		{"not yet valid within the leeway", func(c *Claims) { c.NotBefore = jwt.NewNumericDate(now.Add(10 * time.Second)) }, true},
+		{"issued in the future", func(c *Claims) { c.IssuedAt = jwt.NewNumericDate(now.Add(time.Minute)) }, false},
+		{"issued in the future within the leeway", func(c *Claims) { c.IssuedAt = jwt.NewNumericDate(now.Add(10 * time.Second)) }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.modify(&claims)
+
+			_, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", claims))
+			if tt.valid && err != nil {
+				t.Fatalf("expected the token to be accepted, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("expected the token to be rejected")
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+-- Remove scheduled deletion column and its index from profiles table
+DROP INDEX IF EXISTS idx_profiles_deletion_scheduled_at;
+ALTER TABLE profiles DROP COLUMN IF EXISTS deletion_scheduled_at;
\ No newline at end of file
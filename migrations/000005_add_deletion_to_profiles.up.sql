@@ -0,0 +1,6 @@
+-- Track scheduled account deletions on profiles
+ALTER TABLE profiles
+ADD COLUMN deletion_scheduled_at TIMESTAMP WITH TIME ZONE NULL;
+
+-- Index for the purge worker lookup
+CREATE INDEX IF NOT EXISTS idx_profiles_deletion_scheduled_at ON profiles(deletion_scheduled_at);
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- Remove bio and user preferences from profiles table
+ALTER TABLE profiles
+DROP COLUMN IF EXISTS bio,
+DROP COLUMN IF EXISTS timezone,
+DROP COLUMN IF EXISTS week_start_day,
+DROP COLUMN IF EXISTS locale,
+DROP COLUMN IF EXISTS time_format;
\ No newline at end of file
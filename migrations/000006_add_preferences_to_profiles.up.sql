@@ -0,0 +1,7 @@
+-- Add bio and user preferences to profiles table
+ALTER TABLE profiles
+ADD COLUMN bio TEXT NOT NULL DEFAULT '',
+ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+ADD COLUMN week_start_day SMALLINT NOT NULL DEFAULT 1,
+ADD COLUMN locale VARCHAR(35) NOT NULL DEFAULT 'en-US',
+ADD COLUMN time_format VARCHAR(3) NOT NULL DEFAULT '24h';
\ No newline at end of file
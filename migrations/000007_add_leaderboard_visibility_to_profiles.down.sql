@@ -0,0 +1,2 @@
+-- Remove leaderboard visibility setting from profiles table
+ALTER TABLE profiles DROP COLUMN IF EXISTS leaderboard_visibility;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Add leaderboard visibility setting to profiles table
+ALTER TABLE profiles
+ADD COLUMN leaderboard_visibility VARCHAR(16) NOT NULL DEFAULT 'public';
\ No newline at end of file
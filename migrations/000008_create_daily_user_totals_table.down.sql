@@ -0,0 +1,2 @@
+-- Drop daily_user_totals table
+DROP TABLE IF EXISTS daily_user_totals;
\ No newline at end of file
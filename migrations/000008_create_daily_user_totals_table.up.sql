@@ -0,0 +1,14 @@
+-- Create daily_user_totals table
+-- The server fills it from existing time entries on startup while it is empty
+-- (make stats-rebuild does the same by hand)
+CREATE TABLE IF NOT EXISTS daily_user_totals (
+    user_id UUID NOT NULL,
+    local_date DATE NOT NULL,
+    project_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    seconds BIGINT NOT NULL DEFAULT 0,
+    sessions INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, local_date, project_id)
+);
+
+-- Create index for date range queries
+CREATE INDEX IF NOT EXISTS idx_daily_user_totals_local_date ON daily_user_totals(local_date);
\ No newline at end of file
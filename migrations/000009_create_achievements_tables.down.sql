@@ -0,0 +1,3 @@
+-- Drop achievements tables
+DROP TABLE IF EXISTS user_achievements;
+DROP TABLE IF EXISTS achievements;
\ No newline at end of file
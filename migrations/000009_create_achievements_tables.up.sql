@@ -0,0 +1,31 @@
+-- Create achievements table
+CREATE TABLE IF NOT EXISTS achievements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code VARCHAR(64) NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    description TEXT,
+    icon TEXT,
+    criteria VARCHAR(32) NOT NULL,
+    threshold INTEGER NOT NULL,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create user_achievements table
+CREATE TABLE IF NOT EXISTS user_achievements (
+    user_id UUID NOT NULL,
+    achievement_id UUID NOT NULL REFERENCES achievements(id) ON DELETE CASCADE,
+    unlocked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, achievement_id)
+);
+
+-- Seed default achievements
+INSERT INTO achievements (code, name, description, icon, criteria, threshold, sort_order) VALUES
+    ('first_entry', 'First Steps', 'Track your first session', '🚀', 'total_sessions', 1, 10),
+    ('streak_7', 'On a Roll', 'Track time 7 days in a row', '🔥', 'streak_days', 7, 20),
+    ('streak_30', 'Habit Formed', 'Track time 30 days in a row', '📅', 'streak_days', 30, 30),
+    ('streak_100', 'Unstoppable', 'Track time 100 days in a row', '💯', 'streak_days', 100, 40),
+    ('ten_hour_day', 'Marathon', 'Track 10 hours in a single day', '⏱️', 'day_hours', 10, 50),
+    ('early_bird', 'Early Bird', 'Start a session before 6 AM', '🌅', 'start_before_hour', 6, 60),
+    ('night_owl', 'Night Owl', 'Start a session after 10 PM', '🦉', 'start_after_hour', 22, 70)
+ON CONFLICT (code) DO NOTHING;
\ No newline at end of file
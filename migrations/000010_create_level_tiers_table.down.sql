@@ -0,0 +1,2 @@
+-- Drop level_tiers table
+DROP TABLE IF EXISTS level_tiers;
\ No newline at end of file
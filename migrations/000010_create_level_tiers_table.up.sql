@@ -0,0 +1,11 @@
+-- Create level_tiers table
+-- The server seeds the default tiers (models.DefaultLevelTiers) on startup while it is empty
+CREATE TABLE IF NOT EXISTS level_tiers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    min_hours NUMERIC NOT NULL UNIQUE,
+    color VARCHAR(7) NOT NULL,
+    icon TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
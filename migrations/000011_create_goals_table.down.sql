@@ -0,0 +1,2 @@
+-- Drop goals table
+DROP TABLE IF EXISTS goals;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- Create goals table
+CREATE TABLE IF NOT EXISTS goals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    project_id UUID NULL,
+    period VARCHAR(16) NOT NULL,
+    target_minutes INTEGER NOT NULL,
+    weekdays INTEGER NOT NULL DEFAULT 127,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals(user_id);
+CREATE INDEX IF NOT EXISTS idx_goals_project_id ON goals(project_id);
\ No newline at end of file
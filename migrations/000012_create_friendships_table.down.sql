@@ -0,0 +1,2 @@
+-- Drop friendships table
+DROP TABLE IF EXISTS friendships;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- Create friendships table
+CREATE TABLE IF NOT EXISTS friendships (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    requester_id UUID NOT NULL,
+    addressee_id UUID NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (requester_id <> addressee_id)
+);
+
+-- Create indexes
+-- One friendship or request per pair of users, whichever of them is the requester;
+-- blocks are kept per direction so each user's block exists independently of the other's
+CREATE UNIQUE INDEX IF NOT EXISTS idx_friendships_pair ON friendships(LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id)) WHERE status <> 'blocked';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_friendships_direction ON friendships(requester_id, addressee_id);
+CREATE INDEX IF NOT EXISTS idx_friendships_addressee_id ON friendships(addressee_id);
\ No newline at end of file
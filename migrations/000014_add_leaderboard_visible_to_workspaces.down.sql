@@ -0,0 +1,2 @@
+-- Remove leaderboard opt-in from workspaces
+ALTER TABLE workspaces DROP COLUMN IF EXISTS leaderboard_visible;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Add leaderboard opt-in to workspaces
+ALTER TABLE workspaces
+ADD COLUMN IF NOT EXISTS leaderboard_visible BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Drop timesheets tables
+DROP TABLE IF EXISTS timesheet_events;
+DROP TABLE IF EXISTS timesheets;
\ No newline at end of file
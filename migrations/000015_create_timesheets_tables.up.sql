@@ -0,0 +1,28 @@
+-- Create timesheets table
+CREATE TABLE IF NOT EXISTS timesheets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    week_start DATE NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    submitted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reviewed_by UUID NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE NULL,
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create timesheet_events table
+CREATE TABLE IF NOT EXISTS timesheet_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    timesheet_id UUID NOT NULL REFERENCES timesheets(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_timesheets_user_week ON timesheets(user_id, week_start);
+CREATE INDEX IF NOT EXISTS idx_timesheets_status ON timesheets(status);
+CREATE INDEX IF NOT EXISTS idx_timesheet_events_timesheet_id ON timesheet_events(timesheet_id);
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Drop period_locks table
+DROP TABLE IF EXISTS period_locks;
\ No newline at end of file
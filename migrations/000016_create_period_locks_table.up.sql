@@ -0,0 +1,11 @@
+-- Create period_locks table
+CREATE TABLE IF NOT EXISTS period_locks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NULL,
+    locked_before DATE NOT NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_period_locks_user_id ON period_locks(user_id);
\ No newline at end of file
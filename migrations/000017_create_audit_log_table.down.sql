@@ -0,0 +1,2 @@
+-- Drop audit_log table
+DROP TABLE IF EXISTS audit_log;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Drop personal_access_tokens table
+DROP TABLE IF EXISTS personal_access_tokens;
\ No newline at end of file
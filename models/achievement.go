@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Achievement criteria understood by the achievements package
+// Threshold is interpreted according to the criteria
+const (
+	CriteriaTotalSessions   = "total_sessions"    // At least Threshold stopped entries
+	CriteriaStreakDays      = "streak_days"       // A current streak of at least Threshold days
+	CriteriaDayHours        = "day_hours"         // At least Threshold hours tracked on one local day
+	CriteriaStartBeforeHour = "start_before_hour" // An entry started before Threshold o'clock local time
+	CriteriaStartAfterHour  = "start_after_hour"  // An entry started at or after Threshold o'clock local time
+)
+
+// Achievement is a badge definition users can unlock
+type Achievement struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Code        string    `json:"code" gorm:"type:varchar(64);uniqueIndex;not null"`
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description"`
+	Icon        string    `json:"icon"`
+	Criteria    string    `json:"criteria" gorm:"type:varchar(32);not null"`
+	Threshold   int       `json:"threshold" gorm:"not null"`
+	SortOrder   int       `json:"sort_order" gorm:"not null;default:0"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UserAchievement records when a user unlocked an achievement
+type UserAchievement struct {
+	UserID        uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	AchievementID uuid.UUID `json:"achievement_id" gorm:"type:uuid;primaryKey"`
+	UnlockedAt    time.Time `json:"unlocked_at" gorm:"not null"`
+
+	Achievement Achievement `json:"achievement,omitempty" gorm:"-:migration;foreignKey:AchievementID;references:ID"`
+}
+
+type AchievementResponse struct {
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Icon        string     `json:"icon"`
+	Unlocked    bool       `json:"unlocked"`
+	UnlockedAt  *time.Time `json:"unlocked_at,omitempty"`
+}
+
+// DefaultAchievements are the achievements shipped with the application
+// Keep in sync with the seed in migrations/000009_create_achievements_tables.up.sql
+var DefaultAchievements = []Achievement{
+	{Code: "first_entry", Name: "First Steps", Description: "Track your first session", Icon: "🚀", Criteria: CriteriaTotalSessions, Threshold: 1, SortOrder: 10},
+	{Code: "streak_7", Name: "On a Roll", Description: "Track time 7 days in a row", Icon: "🔥", Criteria: CriteriaStreakDays, Threshold: 7, SortOrder: 20},
+	{Code: "streak_30", Name: "Habit Formed", Description: "Track time 30 days in a row", Icon: "📅", Criteria: CriteriaStreakDays, Threshold: 30, SortOrder: 30},
+	{Code: "streak_100", Name: "Unstoppable", Description: "Track time 100 days in a row", Icon: "💯", Criteria: CriteriaStreakDays, Threshold: 100, SortOrder: 40},
+	{Code: "ten_hour_day", Name: "Marathon", Description: "Track 10 hours in a single day", Icon: "⏱️", Criteria: CriteriaDayHours, Threshold: 10, SortOrder: 50},
+	{Code: "early_bird", Name: "Early Bird", Description: "Start a session before 6 AM", Icon: "🌅", Criteria: CriteriaStartBeforeHour, Threshold: 6, SortOrder: 60},
+	{Code: "night_owl", Name: "Night Owl", Description: "Start a session after 10 PM", Icon: "🦉", Criteria: CriteriaStartAfterHour, Threshold: 22, SortOrder: 70},
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit log actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// Audited entity types
+const (
+	AuditEntityTimeEntry = "time_entry"
+	AuditEntityProject   = "project"
+	AuditEntityProfile   = "profile"
+)
+
+// AuditLog records one change to an audited entity
+// Changes maps each changed field to its value before and after the change
+type AuditLog struct {
+	ID         uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ActorID    *uuid.UUID      `json:"actor_id" gorm:"type:uuid;index"` // nil for changes made by the system
+	Action     string          `json:"action" gorm:"type:varchar(16);not null"`
+	EntityType string          `json:"entity_type" gorm:"type:varchar(32);not null;index:idx_audit_log_entity"`
+	EntityID   uuid.UUID       `json:"entity_id" gorm:"type:uuid;not null;index:idx_audit_log_entity"`
+	Changes    json.RawMessage `json:"changes" gorm:"type:jsonb;not null"`
+	RequestID  string          `json:"request_id" gorm:"index"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"index"`
+}
+
+// TableName uses the singular audit_log table name
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+// AuditChange is a field's value before and after a change
+// Before is null on create and After is null on delete
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type PaginatedAuditLogResponse struct {
+	Data       []AuditLog `json:"data"`
+	Page       int        `json:"page"`
+	Limit      int        `json:"limit"`
+	Total      int64      `json:"total"`
+	TotalPages int        `json:"total_pages"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DailyUserTotal holds the time a user tracked on one calendar day (in their timezone) for one project
+// Rows are maintained by the stats package whenever time entries change
+type DailyUserTotal struct {
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	LocalDate time.Time `json:"local_date" gorm:"type:date;primaryKey"`
+	ProjectID uuid.UUID `json:"project_id" gorm:"type:uuid;primaryKey"` // uuid.Nil for entries without a project
+	Seconds   int64     `json:"seconds" gorm:"not null;default:0"`
+	Sessions  int       `json:"sessions" gorm:"not null;default:0"`
+}
+
+// TableName specifies the table name for daily totals
+func (DailyUserTotal) TableName() string {
+	return "daily_user_totals"
+}
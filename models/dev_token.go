@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DevTokenRequest asks for a development token; every field is optional
+type DevTokenRequest struct {
+	UserID     *uuid.UUID `json:"user_id"` // defaults to a new random user
+	Email      string     `json:"email" binding:"omitempty,email"`
+	Role       string     `json:"role"`                                            // defaults to authenticated
+	TTLMinutes int        `json:"ttl_minutes" binding:"omitempty,min=1,max=10080"` // defaults to 60
+}
+
+type DevTokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	UserID      uuid.UUID `json:"user_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Friendship statuses
+const (
+	FriendshipPending  = "pending"
+	FriendshipAccepted = "accepted"
+	FriendshipBlocked  = "blocked"
+)
+
+// Friendship links two users; there is at most one friendship or request per pair of users, in either direction
+// For blocks the requester is the user who blocked the addressee, and each user's block is its own row
+type Friendship struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	RequesterID uuid.UUID `json:"requester_id" gorm:"type:uuid;not null;uniqueIndex:idx_friendships_pair,expression:LEAST(requester_id\\,addressee_id),priority:1,where:status <> 'blocked';uniqueIndex:idx_friendships_direction,priority:1"`
+	AddresseeID uuid.UUID `json:"addressee_id" gorm:"type:uuid;not null;uniqueIndex:idx_friendships_pair,expression:GREATEST(requester_id\\,addressee_id),priority:2;uniqueIndex:idx_friendships_direction,priority:2;index"`
+	Status      string    `json:"status" gorm:"type:varchar(16);not null;default:pending"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Other returns the ID of the user on the other side of the friendship
+func (f Friendship) Other(userID uuid.UUID) uuid.UUID {
+	if f.RequesterID == userID {
+		return f.AddresseeID
+	}
+	return f.RequesterID
+}
+
+type FriendRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// FriendResponse describes the other user of a friendship, request or block
+type FriendResponse struct {
+	FriendshipID      uuid.UUID `json:"friendship_id"`
+	UserID            uuid.UUID `json:"user_id"`
+	Name              string    `json:"name"`
+	ProfilePictureURL *string   `json:"profile_picture_url"`
+	Since             time.Time `json:"since"`
+}
+
+type FriendRequestsResponse struct {
+	Incoming []FriendResponse `json:"incoming"`
+	Outgoing []FriendResponse `json:"outgoing"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Goal periods
+const (
+	GoalPeriodDaily  = "daily"
+	GoalPeriodWeekly = "weekly"
+)
+
+// AllWeekdays is the weekday mask of a goal that applies every day (bit 0 = Sunday)
+const AllWeekdays = 0x7f
+
+type Goal struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID        uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	ProjectID     *uuid.UUID `json:"project_id" gorm:"type:uuid;index"` // nil counts time on every project
+	Period        string     `json:"period" gorm:"type:varchar(16);not null"`
+	TargetMinutes int        `json:"target_minutes" gorm:"not null"`
+	Weekdays      int        `json:"weekdays" gorm:"not null;default:127"` // bit mask, only used by daily goals
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Project *Project `json:"project,omitempty" gorm:"-:migration;foreignKey:ProjectID;references:ID"`
+}
+
+// AppliesOn reports whether a daily goal applies on the given weekday
+func (g Goal) AppliesOn(weekday time.Weekday) bool {
+	return g.Weekdays&(1<<uint(weekday)) != 0
+}
+
+type GoalCreateRequest struct {
+	Period        string     `json:"period" binding:"required,oneof=daily weekly"`
+	TargetMinutes int        `json:"target_minutes" binding:"required,min=1,max=10080"`
+	Weekdays      []int      `json:"weekdays" binding:"omitempty,dive,min=0,max=6"` // defaults to every day
+	ProjectID     *uuid.UUID `json:"project_id"`
+}
+
+type GoalUpdateRequest struct {
+	TargetMinutes *int       `json:"target_minutes" binding:"omitempty,min=1,max=10080"`
+	Weekdays      []int      `json:"weekdays" binding:"omitempty,min=1,dive,min=0,max=6"`
+	ProjectID     *uuid.UUID `json:"project_id"`
+}
+
+type GoalResponse struct {
+	ID            uuid.UUID        `json:"id"`
+	Period        string           `json:"period"`
+	TargetMinutes int              `json:"target_minutes"`
+	Weekdays      []int            `json:"weekdays"`
+	Project       *ProjectResponse `json:"project"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// GoalProgress is a goal's progress in its current period
+type GoalProgress struct {
+	Goal           GoalResponse `json:"goal"`
+	PeriodStart    string       `json:"period_start"`
+	PeriodEnd      string       `json:"period_end"`
+	Applicable     bool         `json:"applicable"` // false for daily goals that do not apply today
+	TrackedMinutes int          `json:"tracked_minutes"`
+	Percent        float32      `json:"percent"`
+	Completed      bool         `json:"completed"`
+}
+
+type GoalProgressResponse struct {
+	Date      string         `json:"date"`
+	WeekStart string         `json:"week_start"`
+	Daily     []GoalProgress `json:"daily"`
+	Weekly    []GoalProgress `json:"weekly"`
+}
+
+// GoalDay is the outcome of a user's daily goals on one day
+type GoalDay struct {
+	Date       string `json:"date"`
+	Applicable bool   `json:"applicable"` // false when no daily goal applies, so the day does not break streaks
+	Completed  bool   `json:"completed"`
+}
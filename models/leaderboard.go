@@ -0,0 +1,44 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Leaderboard periods
+const (
+	LeaderboardPeriodWeek  = "week"
+	LeaderboardPeriodMonth = "month"
+	LeaderboardPeriodYear  = "year"
+	LeaderboardPeriodAll   = "all"
+)
+
+// Leaderboard scopes
+const (
+	LeaderboardScopeGlobal  = "global"
+	LeaderboardScopeFriends = "friends"
+)
+
+type LeaderboardEntry struct {
+	UserID            uuid.UUID `json:"user_id"`
+	Name              string    `json:"name"`
+	ProfilePictureURL *string   `json:"profile_picture_url,omitempty"`
+	TotalHours        float32   `json:"total_hours"`
+	Level             string    `json:"level"`
+	LevelColor        string    `json:"level_color"`
+	LevelIcon         string    `json:"level_icon"`
+	Rank              int       `json:"rank"`
+	CurrentStreak     int       `json:"current_streak"`
+	LongestStreak     int       `json:"longest_streak"`
+	IsCurrentUser     bool      `json:"is_current_user"`
+}
+
+// TeamLeaderboardEntry ranks a workspace by the hours its members tracked
+type TeamLeaderboardEntry struct {
+	TeamID        uuid.UUID `json:"team_id"`
+	Name          string    `json:"name"`
+	TotalHours    float32   `json:"total_hours"`
+	MemberCount   int       `json:"member_count"`
+	AverageHours  float32   `json:"average_hours"` // per member
+	Rank          int       `json:"rank"`
+	IsCurrentTeam bool      `json:"is_current_team"` // the caller is a member
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LevelTier is a level users reach once they tracked at least MinHours in total
+type LevelTier struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Name      string    `json:"name" gorm:"not null"`
+	MinHours  float64   `json:"min_hours" gorm:"not null;uniqueIndex"`
+	Color     string    `json:"color" gorm:"type:varchar(7);not null"`
+	Icon      string    `json:"icon"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type LevelTierRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	MinHours *float64 `json:"min_hours" binding:"required,min=0"`
+	Color    string   `json:"color" binding:"required,hexcolor,len=7"`
+	Icon     string   `json:"icon"`
+}
+
+// LevelProgress describes a user's level and how far they are from the next one
+type LevelProgress struct {
+	Level            string  `json:"level"`
+	LevelColor       string  `json:"level_color"`
+	LevelIcon        string  `json:"level_icon"`
+	NextLevel        *string `json:"next_level,omitempty"`
+	HoursToNextLevel float32 `json:"hours_to_next_level"`
+	LevelProgress    float32 `json:"level_progress"` // percent of the way from the current to the next level
+}
+
+// DefaultLevelTiers are the level tiers shipped with the application, and their only definition:
+// database.Seed stores them while the level_tiers table is empty, and they apply whenever it is
+var DefaultLevelTiers = []LevelTier{
+	{Name: "Newbie", MinHours: 0, Color: "#00d4ff", Icon: "🌱"},
+	{Name: "Beginner", MinHours: 1, Color: "#00e5ff", Icon: "🐣"},
+	{Name: "Learner", MinHours: 5, Color: "#1de9b6", Icon: "📘"},
+	{Name: "Apprentice", MinHours: 10, Color: "#00e676", Icon: "🛠️"},
+	{Name: "Practitioner", MinHours: 25, Color: "#76ff03", Icon: "⚙️"},
+	{Name: "Skilled", MinHours: 50, Color: "#aeea00", Icon: "🎯"},
+	{Name: "Experienced", MinHours: 100, Color: "#ffd600", Icon: "⭐"},
+	{Name: "Professional", MinHours: 150, Color: "#ffab00", Icon: "💼"},
+	{Name: "Expert", MinHours: 200, Color: "#ff6d00", Icon: "🧠"},
+	{Name: "Veteran", MinHours: 300, Color: "#ff3d00", Icon: "🎖️"},
+	{Name: "Master", MinHours: 400, Color: "#ff1744", Icon: "🥋"},
+	{Name: "Grandmaster", MinHours: 500, Color: "#f50057", Icon: "♟️"},
+	{Name: "Elite", MinHours: 750, Color: "#d500f9", Icon: "💎"},
+	{Name: "Champion", MinHours: 1000, Color: "#aa00ff", Icon: "🏆"},
+	{Name: "Hero", MinHours: 1500, Color: "#651fff", Icon: "🦸"},
+	{Name: "Legend", MinHours: 2000, Color: "#3d5afe", Icon: "📜"},
+	{Name: "Mythic", MinHours: 3000, Color: "#2979ff", Icon: "🐉"},
+	{Name: "Immortal", MinHours: 4000, Color: "#00b0ff", Icon: "♾️"},
+	{Name: "Divine", MinHours: 5000, Color: "#00e5ff", Icon: "👼"},
+	{Name: "Eternal", MinHours: 7500, Color: "#1de9b6", Icon: "🌌"},
+}
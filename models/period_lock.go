@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PeriodLock closes every day before LockedBefore to changes, for one user or for everyone
+// Days are the user's local calendar days, matching the daily totals
+type PeriodLock struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID       *uuid.UUID `json:"user_id" gorm:"type:uuid;index"` // nil locks every user
+	LockedBefore time.Time  `json:"locked_before" gorm:"type:date;not null"`
+	CreatedBy    uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type PeriodLockRequest struct {
+	UserID       *uuid.UUID `json:"user_id"`                          // omit to lock every user
+	LockedBefore string     `json:"locked_before" binding:"required"` // YYYY-MM-DD
+}
+
+type PeriodLockResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	UserID       *uuid.UUID `json:"user_id"`
+	LockedBefore string     `json:"locked_before"`
+	CreatedBy    uuid.UUID  `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Personal access token scopes, one read and one write scope per resource
+const (
+	ScopeTimeEntriesRead  = "time_entries:read"
+	ScopeTimeEntriesWrite = "time_entries:write"
+	ScopeProjectsRead     = "projects:read"
+	ScopeProjectsWrite    = "projects:write"
+	ScopeProfileRead      = "profile:read"
+	ScopeProfileWrite     = "profile:write"
+	ScopeGoalsRead        = "goals:read"
+	ScopeGoalsWrite       = "goals:write"
+	ScopeTimesheetsRead   = "timesheets:read"
+	ScopeTimesheetsWrite  = "timesheets:write"
+	ScopeWorkspacesRead   = "workspaces:read"
+	ScopeWorkspacesWrite  = "workspaces:write"
+	ScopeFriendsRead      = "friends:read"
+	ScopeFriendsWrite     = "friends:write"
+	ScopeLeaderboardRead  = "leaderboard:read"
+)
+
+// PersonalAccessToken lets scripts act as a user with a limited set of scopes
+// Only a hash of the token is stored
+type PersonalAccessToken struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name       string     `json:"name" gorm:"not null"`
+	TokenHash  string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(16);not null"` // Start of the token, to recognize it
+	Scopes     string     `json:"scopes" gorm:"not null"`                  // Space-separated
+	ExpiresAt  *time.Time `json:"expires_at"`                              // nil never expires
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type PersonalAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=time_entries:read time_entries:write projects:read projects:write profile:read profile:write goals:read goals:write timesheets:read timesheets:write workspaces:read workspaces:write friends:read friends:write leaderboard:read"`
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1,max=365"` // omit for a token that never expires
+}
+
+type PersonalAccessTokenResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Token      string     `json:"token,omitempty"` // Only returned when the token is created
+}
@@ -6,13 +6,33 @@ import (
 	"github.com/google/uuid"
 )
 
+// Time formats a user can prefer
+const (
+	TimeFormat12h = "12h"
+	TimeFormat24h = "24h"
+)
+
+// Leaderboard visibility settings
+const (
+	VisibilityPublic  = "public"  // Ranked and shown to everyone
+	VisibilityFriends = "friends" // Ranked, but only shown by name to friends
+	VisibilityHidden  = "hidden"  // Not ranked at all
+)
+
 // Profile represents the user profile table in public schema
 type Profile struct {
-	ID                uuid.UUID `json:"id" gorm:"primaryKey;column:id"`
-	Name              string    `json:"name" gorm:"column:name"`
-	ProfilePictureURL *string   `json:"profile_picture_url,omitempty" gorm:"column:profile_picture_url"`
-	CreatedAt         time.Time `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt         time.Time `json:"updated_at" gorm:"column:updated_at"`
+	ID                  uuid.UUID  `json:"id" gorm:"primaryKey;column:id"`
+	Name                string     `json:"name" gorm:"column:name"`
+	Bio                 string     `json:"bio" gorm:"column:bio;default:''"`
+	ProfilePictureURL   *string    `json:"profile_picture_url,omitempty" gorm:"column:profile_picture_url"`
+	Timezone            string     `json:"timezone" gorm:"column:timezone;default:UTC"`
+	WeekStartDay        int        `json:"week_start_day" gorm:"column:week_start_day;default:1"` // 0 = Sunday, 1 = Monday, ...
+	Locale              string     `json:"locale" gorm:"column:locale;default:en-US"`
+	TimeFormat          string     `json:"time_format" gorm:"column:time_format;default:24h"`
+	Visibility          string     `json:"leaderboard_visibility" gorm:"column:leaderboard_visibility;default:public"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" gorm:"column:deletion_scheduled_at"`
+	CreatedAt           time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" gorm:"column:updated_at"`
 }
 
 // CreateProfileRequest represents the request body for creating a profile
@@ -20,7 +40,46 @@ type CreateProfileRequest struct {
 	Name string `json:"name" binding:"required"`
 }
 
+// UpdateProfileRequest represents the request body for updating a profile
+// Only the fields that are present are updated
+type UpdateProfileRequest struct {
+	Name         *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Bio          *string `json:"bio" binding:"omitempty,max=500"`
+	Timezone     *string `json:"timezone"`
+	WeekStartDay *int    `json:"week_start_day" binding:"omitempty,min=0,max=6"`
+	Locale       *string `json:"locale"`
+	TimeFormat   *string `json:"time_format" binding:"omitempty,oneof=12h 24h"`
+	Visibility   *string `json:"leaderboard_visibility" binding:"omitempty,oneof=public friends hidden"`
+}
+
+// DeleteAccountRequest represents the request body for deleting an account
+// Confirm must be set to "DELETE" to schedule the deletion
+type DeleteAccountRequest struct {
+	Confirm string `json:"confirm" binding:"required"`
+}
+
 // TableName specifies the table name in the public schema
 func (Profile) TableName() string {
 	return "public.profiles"
-}
\ No newline at end of file
+}
+
+// Location returns the profile's time zone, falling back to UTC if it is unset or unknown
+func (p Profile) Location() *time.Location {
+	if p.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// StartOfWeek returns midnight of the first day of the week containing t,
+// in the profile's time zone and honoring its week start day
+func (p Profile) StartOfWeek(t time.Time) time.Time {
+	local := t.In(p.Location())
+	offset := (int(local.Weekday()) - p.WeekStartDay + 7) % 7
+	day := local.AddDate(0, 0, -offset)
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+}
@@ -7,9 +7,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// Every user gets a personal "General" project for time entries without a project
+const (
+	GeneralProjectName  = "General"
+	DefaultProjectColor = "#3B82F6" // Blue
+)
+
 type Project struct {
 	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
 	UserID      uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	WorkspaceID *uuid.UUID     `json:"workspace_id" gorm:"type:uuid;index"` // nil for personal projects
 	Name        string         `json:"name" gorm:"not null"`
 	Description string         `json:"description"`
 	Color       string         `json:"color" gorm:"type:varchar(7);default:'#3B82F6'"`
@@ -23,9 +30,10 @@ type Project struct {
 }
 
 type ProjectCreateRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	Color       string `json:"color"`
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description"`
+	Color       string     `json:"color"`
+	WorkspaceID *uuid.UUID `json:"workspace_id"` // Creates a shared project in the workspace
 }
 
 type ProjectUpdateRequest struct {
@@ -35,11 +43,12 @@ type ProjectUpdateRequest struct {
 }
 
 type ProjectResponse struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Color       string    `json:"color"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          uuid.UUID  `json:"id"`
+	WorkspaceID *uuid.UUID `json:"workspace_id,omitempty"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Color       string     `json:"color"`
+	CreatedAt   time.Time  `json:"created_at"`
 }
 
 type PaginatedProjectResponse struct {
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Team report intervals
+const (
+	ReportIntervalDay   = "day"
+	ReportIntervalWeek  = "week"
+	ReportIntervalMonth = "month"
+)
+
+// TeamReport breaks down the hours of a workspace's members by project and period
+type TeamReport struct {
+	TeamID     uuid.UUID          `json:"team_id"`
+	From       string             `json:"from"`
+	To         string             `json:"to"`
+	Interval   string             `json:"interval"`
+	TotalHours float32            `json:"total_hours"`
+	Members    []TeamReportMember `json:"members"`
+}
+
+type TeamReportMember struct {
+	UserID     uuid.UUID           `json:"user_id"`
+	Name       string              `json:"name"`
+	Role       string              `json:"role"`
+	TotalHours float32             `json:"total_hours"`
+	Projects   []TeamReportProject `json:"projects"`
+	Periods    []TeamReportPeriod  `json:"periods"`
+}
+
+// TeamReportProject is a member's time on one of the workspace's projects
+type TeamReportProject struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+	Hours     float32   `json:"hours"`
+}
+
+type TeamReportPeriod struct {
+	Start string  `json:"start"`
+	Hours float32 `json:"hours"`
+}
@@ -35,11 +35,15 @@ type TimeEntryUpdateRequest struct {
 
 type TimeEntryResponse struct {
 	ID        uuid.UUID        `json:"id"`
+	UserID    uuid.UUID        `json:"user_id"`
 	Project   *ProjectResponse `json:"project"`
 	StartTime time.Time        `json:"start_time"`
 	EndTime   *time.Time       `json:"end_time"`
 	Duration  int64            `json:"duration"`
 	CreatedAt time.Time        `json:"created_at"`
+
+	// Achievements unlocked by stopping this entry, only set on stop
+	UnlockedAchievements []AchievementResponse `json:"unlocked_achievements,omitempty"`
 }
 
 type PaginatedTimeEntriesResponse struct {
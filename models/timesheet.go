@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Timesheet statuses
+const (
+	TimesheetSubmitted = "submitted"
+	TimesheetApproved  = "approved"
+	TimesheetRejected  = "rejected"
+)
+
+// Timesheet covers one week of a user's time entries, starting on their week start day
+// Entries of approved timesheets can no longer be changed
+type Timesheet struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_timesheets_user_week"`
+	WeekStart   time.Time  `json:"week_start" gorm:"type:date;not null;uniqueIndex:idx_timesheets_user_week"`
+	Status      string     `json:"status" gorm:"type:varchar(16);not null;index"`
+	SubmittedAt time.Time  `json:"submitted_at" gorm:"not null"`
+	ReviewedBy  *uuid.UUID `json:"reviewed_by" gorm:"type:uuid"`
+	ReviewedAt  *time.Time `json:"reviewed_at"`
+	Comment     string     `json:"comment"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TimesheetEvent records who submitted, approved or rejected a timesheet and when
+type TimesheetEvent struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	TimesheetID uuid.UUID `json:"timesheet_id" gorm:"type:uuid;not null;index"`
+	ActorID     uuid.UUID `json:"actor_id" gorm:"type:uuid;not null"`
+	Action      string    `json:"action" gorm:"type:varchar(16);not null"` // One of the timesheet statuses
+	Comment     string    `json:"comment"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type SubmitTimesheetRequest struct {
+	WeekStart string `json:"week_start"` // YYYY-MM-DD, defaults to the previous week
+}
+
+type ReviewTimesheetRequest struct {
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+type TimesheetEventResponse struct {
+	ActorID   uuid.UUID `json:"actor_id"`
+	Action    string    `json:"action"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TimesheetResponse struct {
+	ID          uuid.UUID                `json:"id"`
+	UserID      uuid.UUID                `json:"user_id"`
+	WeekStart   string                   `json:"week_start"`
+	WeekEnd     string                   `json:"week_end"`
+	Status      string                   `json:"status"`
+	TotalHours  float32                  `json:"total_hours"`
+	Entries     int                      `json:"entries"`
+	SubmittedAt time.Time                `json:"submitted_at"`
+	ReviewedBy  *uuid.UUID               `json:"reviewed_by"`
+	ReviewedAt  *time.Time               `json:"reviewed_at"`
+	Comment     string                   `json:"comment"`
+	Events      []TimesheetEventResponse `json:"events,omitempty"` // Only set for a single timesheet
+}
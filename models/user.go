@@ -18,17 +18,29 @@ func (User) TableName() string {
 }
 
 type UserResponse struct {
-	ID                uuid.UUID `json:"id"`
-	Name              string    `json:"name"`
-	Email             string    `json:"email"`
-	ProfilePictureURL *string   `json:"profile_picture_url,omitempty"`
-	TotalHours        float32   `json:"total_hours"`
-	TotalSessions     int       `json:"total_sessions"`
-	CurrentStreak     int       `json:"current_streak"`
-	DayilyAvg         float32   `json:"dayily_avg"`
-	Rank              int       `json:"rank"`
-	Level             string    `json:"level"`
-	LevelColor        string    `json:"level_color"`
-	CreatedAt         string    `json:"created_at"`
-}
+	ID                 uuid.UUID `json:"id"`
+	Name               string    `json:"name"`
+	Email              string    `json:"email"`
+	ProfilePictureURL  *string   `json:"profile_picture_url,omitempty"`
+	Bio                string    `json:"bio"`
+	Timezone           string    `json:"timezone"`
+	WeekStartDay       int       `json:"week_start_day"`
+	Locale             string    `json:"locale"`
+	TimeFormat         string    `json:"time_format"`
+	Visibility         string    `json:"leaderboard_visibility"`
+	TotalHours         float32   `json:"total_hours"`
+	TotalSessions      int       `json:"total_sessions"`
+	CurrentStreak      int       `json:"current_streak"`
+	LongestStreak      int       `json:"longest_streak"`
+	LongestStreakStart *string   `json:"longest_streak_start,omitempty"`
+	LongestStreakEnd   *string   `json:"longest_streak_end,omitempty"`
+	DayilyAvg          float32   `json:"dayily_avg"`
+	Rank               int       `json:"rank"` // 0 when hidden from the leaderboard
+	CreatedAt          string    `json:"created_at"`
+
+	// Level, next level and progress towards it, based on total hours
+	LevelProgress
 
+	// Unlocked achievements, most recent first
+	Achievements []AchievementResponse `json:"achievements"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Workspace roles, from most to least privileged
+const (
+	WorkspaceRoleOwner  = "owner"
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+	WorkspaceRoleViewer = "viewer"
+)
+
+// Workspace groups users who share projects
+type Workspace struct {
+	ID                 uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Name               string    `json:"name" gorm:"not null"`
+	LeaderboardVisible bool      `json:"leaderboard_visible" gorm:"not null;default:false"` // opts into the team leaderboard
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// WorkspaceMember gives a user a role in a workspace
+type WorkspaceMember struct {
+	WorkspaceID uuid.UUID `json:"workspace_id" gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	Role        string    `json:"role" gorm:"type:varchar(16);not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WorkspaceInvitation invites an email address to join a workspace
+// Only a hash of the accept token is stored
+type WorkspaceInvitation struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	WorkspaceID uuid.UUID  `json:"workspace_id" gorm:"type:uuid;not null;index"`
+	Email       string     `json:"email" gorm:"not null"`
+	Role        string     `json:"role" gorm:"type:varchar(16);not null"`
+	TokenHash   string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	InvitedBy   uuid.UUID  `json:"invited_by" gorm:"type:uuid;not null"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type WorkspaceRequest struct {
+	Name               string `json:"name" binding:"required,max=100"`
+	LeaderboardVisible *bool  `json:"leaderboard_visible"`
+}
+
+type WorkspaceResponse struct {
+	ID                 uuid.UUID `json:"id"`
+	Name               string    `json:"name"`
+	Role               string    `json:"role"` // The caller's role
+	LeaderboardVisible bool      `json:"leaderboard_visible"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type WorkspaceMemberResponse struct {
+	UserID            uuid.UUID `json:"user_id"`
+	Name              string    `json:"name"`
+	Email             string    `json:"email"`
+	ProfilePictureURL *string   `json:"profile_picture_url,omitempty"`
+	Role              string    `json:"role"`
+	JoinedAt          time.Time `json:"joined_at"`
+}
+
+type UpdateWorkspaceMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member viewer"`
+}
+
+type WorkspaceInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=admin member viewer"` // defaults to member
+}
+
+type WorkspaceInvitationResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	Token     string    `json:"token,omitempty"` // Only returned when the invitation is created
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
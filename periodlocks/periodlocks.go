@@ -0,0 +1,44 @@
+package periodlocks
+
+import (
+	"time"
+	"time-tracker/models"
+	"time-tracker/stats"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LockedBefore returns the latest date before which the user's time is locked,
+// combining global locks with the user's own, and false when nothing is locked
+func LockedBefore(db *gorm.DB, userID uuid.UUID) (time.Time, bool, error) {
+	var locks []models.PeriodLock
+	err := db.Where("user_id IS NULL OR user_id = ?", userID).
+		Order("locked_before DESC").
+		Limit(1).
+		Find(&locks).Error
+	if err != nil || len(locks) == 0 {
+		return time.Time{}, false, err
+	}
+	return stats.LocalDate(locks[0].LockedBefore, time.UTC), true, nil
+}
+
+// Locked reports whether t falls on a locked day in the user's timezone
+func Locked(db *gorm.DB, userID uuid.UUID, t time.Time) (bool, error) {
+	_, locked, err := LockedUntil(db, userID, t)
+	return locked, err
+}
+
+// LockedUntil returns the instant the lock covering t ends, the first unlocked midnight
+// in the user's timezone, and false when t is not locked
+func LockedUntil(db *gorm.DB, userID uuid.UUID, t time.Time) (time.Time, bool, error) {
+	before, ok, err := LockedBefore(db, userID)
+	if err != nil || !ok {
+		return time.Time{}, false, err
+	}
+	loc := stats.UserLocation(db, userID)
+	if !stats.LocalDate(t, loc).Before(before) {
+		return time.Time{}, false, nil
+	}
+	return time.Date(before.Year(), before.Month(), before.Day(), 0, 0, 0, 0, loc), true, nil
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time-tracker/achievements"
+	"time-tracker/models"
+
+	"gorm.io/gorm"
+)
+
+type achievementRepository struct {
+	db *gorm.DB
+}
+
+// NewAchievements returns an achievement repository backed by the database
+func NewAchievements(db *gorm.DB) AchievementRepository {
+	return &achievementRepository{db: db}
+}
+
+func (r *achievementRepository) Evaluate(ctx context.Context, entry models.TimeEntry) ([]models.Achievement, error) {
+	var unlocked []models.Achievement
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		unlocked, err = achievements.Evaluate(tx, entry.UserID, entry)
+		return err
+	})
+	return unlocked, err
+}
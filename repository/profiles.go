@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"time-tracker/audit"
+	"time-tracker/goals"
+	"time-tracker/levels"
+	"time-tracker/models"
+	"time-tracker/stats"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type profiles struct {
+	db *gorm.DB
+}
+
+// NewProfiles returns a profile repository backed by the database
+func NewProfiles(db *gorm.DB) ProfileRepository {
+	return &profiles{db: db}
+}
+
+func (r *profiles) Find(ctx context.Context, userID uuid.UUID) (models.Profile, error) {
+	var profile models.Profile
+	err := r.db.WithContext(ctx).Where("id = ?", userID).First(&profile).Error
+	return profile, notFound(err)
+}
+
+func (r *profiles) FindUser(ctx context.Context, userID uuid.UUID) (models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error
+	return user, notFound(err)
+}
+
+// Create creates the profile and audits the change together
+func (r *profiles) Create(ctx context.Context, profile *models.Profile) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(profile).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.FromContext(ctx), models.AuditEntityProfile, profile.ID, nil, *profile)
+	})
+}
+
+// Update applies the updates and audits the change together
+func (r *profiles) Update(ctx context.Context, profile models.Profile, updates map[string]interface{}) (models.Profile, error) {
+	var updated models.Profile
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Profile{}).Where("id = ?", profile.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", profile.ID).First(&updated).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, audit.FromContext(ctx), models.AuditEntityProfile, profile.ID, profile, updated); err != nil {
+			return err
+		}
+
+		// Daily totals are bucketed by local date, so a new timezone moves them
+		if updated.Timezone != profile.Timezone {
+			return stats.RebuildUser(tx, profile.ID)
+		}
+		return nil
+	})
+	return updated, err
+}
+
+// Totals reads the precomputed daily aggregates
+func (r *profiles) Totals(ctx context.Context, userID uuid.UUID) (ProfileTotals, error) {
+	var totals ProfileTotals
+	err := r.db.WithContext(ctx).Model(&models.DailyUserTotal{}).
+		Select("COALESCE(SUM(seconds), 0) AS seconds, COALESCE(SUM(sessions), 0) AS sessions").
+		Where("user_id = ?", userID).
+		Scan(&totals).Error
+	return totals, err
+}
+
+// Streaks derives streaks from active days (or completed daily goals) in the user's timezone
+func (r *profiles) Streaks(ctx context.Context, profile models.Profile, now time.Time) (stats.Streaks, error) {
+	userStreaks, err := goals.Streaks(r.db.WithContext(ctx), []uuid.UUID{profile.ID}, map[uuid.UUID]*time.Location{profile.ID: profile.Location()}, now)
+	if err != nil {
+		return stats.Streaks{}, err
+	}
+	return userStreaks[profile.ID], nil
+}
+
+func (r *profiles) Rank(ctx context.Context, userID uuid.UUID) (int, error) {
+	return stats.UserRank(r.db.WithContext(ctx), userID, time.Time{}.Format(stats.DateFormat))
+}
+
+func (r *profiles) LevelTiers(ctx context.Context) []models.LevelTier {
+	return levels.Tiers(r.db.WithContext(ctx))
+}
+
+func (r *profiles) Achievements(ctx context.Context, userID uuid.UUID) ([]models.UserAchievement, error) {
+	var owned []models.UserAchievement
+	err := r.db.WithContext(ctx).Preload("Achievement").Where("user_id = ?", userID).Order("unlocked_at DESC").Find(&owned).Error
+	return owned, err
+}
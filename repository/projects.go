@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"time-tracker/audit"
+	"time-tracker/models"
+	"time-tracker/periodlocks"
+	"time-tracker/stats"
+	"time-tracker/timesheets"
+	"time-tracker/workspaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type projects struct {
+	db *gorm.DB
+}
+
+// NewProjects returns a project repository backed by the database
+func NewProjects(db *gorm.DB) ProjectRepository {
+	return &projects{db: db}
+}
+
+func (r *projects) FindGeneral(ctx context.Context, userID uuid.UUID) (models.Project, error) {
+	var project models.Project
+	err := r.db.WithContext(ctx).Where("user_id = ? AND name = ?", userID, models.GeneralProjectName).First(&project).Error
+	return project, notFound(err)
+}
+
+func (r *projects) FindAccessible(ctx context.Context, userID, id uuid.UUID) (models.Project, error) {
+	var project models.Project
+	err := r.db.WithContext(ctx).Scopes(workspaces.AccessibleProjects(userID)).Where("id = ?", id).First(&project).Error
+	return project, notFound(err)
+}
+
+func (r *projects) ListAccessible(ctx context.Context, userID uuid.UUID, workspaceID *uuid.UUID, page Page) ([]models.Project, int64, error) {
+	// Personal projects and projects shared in the user's workspaces
+	query := r.db.WithContext(ctx).Model(&models.Project{}).Scopes(workspaces.AccessibleProjects(userID))
+	if workspaceID != nil {
+		query = query.Where("workspace_id = ?", *workspaceID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var projects []models.Project
+	if err := query.Order("created_at DESC").Limit(page.Limit).Offset(page.Offset()).Find(&projects).Error; err != nil {
+		return nil, 0, err
+	}
+	return projects, total, nil
+}
+
+func (r *projects) ProjectRole(ctx context.Context, project models.Project, userID uuid.UUID) (string, error) {
+	return workspaces.ProjectRole(r.db.WithContext(ctx), project, userID)
+}
+
+func (r *projects) WorkspaceRole(ctx context.Context, workspaceID, userID uuid.UUID) (string, error) {
+	return workspaces.Role(r.db.WithContext(ctx), workspaceID, userID)
+}
+
+// Create creates the project and audits the change together
+func (r *projects) Create(ctx context.Context, project *models.Project) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.FromContext(ctx), models.AuditEntityProject, project.ID, nil, *project)
+	})
+}
+
+// Update saves the project and audits the change together
+func (r *projects) Update(ctx context.Context, previous models.Project, project *models.Project) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(project).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.FromContext(ctx), models.AuditEntityProject, project.ID, previous, *project)
+	})
+}
+
+// Delete moves the project's time entries to no project, deletes its goals and the project,
+// audits every change and rebuilds the daily totals of everyone who tracked time on it
+// Entries in locked periods or approved timesheets cannot change, so such projects are kept
+func (r *projects) Delete(ctx context.Context, project models.Project) error {
+	auditCtx := audit.FromContext(ctx)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Shared projects can hold time entries of several users
+		var affectedEntries []models.TimeEntry
+		if err := tx.Where("project_id = ?", project.ID).Find(&affectedEntries).Error; err != nil {
+			return err
+		}
+		for _, entry := range affectedEntries {
+			locked, err := periodlocks.Locked(tx, entry.UserID, entry.StartTime)
+			if err == nil && !locked {
+				locked, err = timesheets.Locked(tx, entry)
+			}
+			if err != nil {
+				return err
+			}
+			if locked {
+				return ErrLocked
+			}
+		}
+
+		// Set project_id to null for all time entries associated with this project
+		if err := tx.Model(&models.TimeEntry{}).Where("project_id = ?", project.ID).Update("project_id", nil).Error; err != nil {
+			return err
+		}
+
+		affectedUserIDs := map[uuid.UUID]bool{}
+		for _, entry := range affectedEntries {
+			affectedUserIDs[entry.UserID] = true
+			updated := entry
+			updated.ProjectID = nil
+			if err := audit.Record(tx, auditCtx, models.AuditEntityTimeEntry, entry.ID, entry, updated); err != nil {
+				return err
+			}
+		}
+
+		// Goals scoped to the project cannot be met anymore
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.Goal{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&project).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, auditCtx, models.AuditEntityProject, project.ID, project, nil); err != nil {
+			return err
+		}
+
+		// Move the project's time to "no project" in the daily totals
+		for affectedUserID := range affectedUserIDs {
+			if err := stats.RebuildUser(tx, affectedUserID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"time-tracker/models"
+	"time-tracker/stats"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned when a record does not exist or is not visible to the user
+var ErrNotFound = errors.New("record not found")
+
+// ErrLocked is returned when a change would touch time entries in a locked period or approved timesheet
+var ErrLocked = errors.New("time entries are locked")
+
+// Page selects one page of a list, numbered from 1
+type Page struct {
+	Number int
+	Limit  int
+}
+
+// Offset returns the number of records before the page
+func (p Page) Offset() int {
+	return (p.Number - 1) * p.Limit
+}
+
+// TimeEntryFilter selects the time entries to list
+type TimeEntryFilter struct {
+	UserID      *uuid.UUID // Only this user's entries
+	WorkspaceID *uuid.UUID // Only entries on the workspace's projects
+}
+
+// TimeEntryRepository stores time entries
+// Writes keep the daily totals in sync and record the change in the audit log,
+// using the audit context carried by ctx
+type TimeEntryRepository interface {
+	// FindOwned returns one of the user's entries
+	FindOwned(ctx context.Context, userID, id uuid.UUID) (models.TimeEntry, error)
+	// FindVisible returns an entry the user owns or manages as a workspace admin, with its project
+	FindVisible(ctx context.Context, userID, id uuid.UUID) (models.TimeEntry, error)
+	// FindWithProject returns an entry with its project
+	FindWithProject(ctx context.Context, id uuid.UUID) (models.TimeEntry, error)
+	// List returns a page of entries, newest first, with their projects and the total count
+	List(ctx context.Context, filter TimeEntryFilter, page Page) ([]models.TimeEntry, int64, error)
+	Create(ctx context.Context, entry *models.TimeEntry) error
+	// Update saves entry, replacing previous, the entry as it was loaded
+	Update(ctx context.Context, previous models.TimeEntry, entry *models.TimeEntry) error
+	Delete(ctx context.Context, entry models.TimeEntry) error
+	// PeriodLocked reports whether an admin closed the user's local day containing t
+	PeriodLocked(ctx context.Context, userID uuid.UUID, t time.Time) (bool, error)
+	// InApprovedTimesheet reports whether the entry belongs to an approved timesheet
+	InApprovedTimesheet(ctx context.Context, entry models.TimeEntry) (bool, error)
+	// LockedUntil returns when the period lock and approved timesheet covering the entry's start
+	// both end, and false when neither covers it
+	LockedUntil(ctx context.Context, entry models.TimeEntry) (time.Time, bool, error)
+}
+
+// ProjectRepository stores projects
+// Writes record the change in the audit log, using the audit context carried by ctx
+type ProjectRepository interface {
+	// FindGeneral returns the user's default "General" project
+	FindGeneral(ctx context.Context, userID uuid.UUID) (models.Project, error)
+	// FindAccessible returns a personal project of the user or a project shared in one of their workspaces
+	FindAccessible(ctx context.Context, userID, id uuid.UUID) (models.Project, error)
+	// ListAccessible returns a page of accessible projects, newest first, and the total count
+	ListAccessible(ctx context.Context, userID uuid.UUID, workspaceID *uuid.UUID, page Page) ([]models.Project, int64, error)
+	// ProjectRole returns the user's role for the project: owner of personal projects,
+	// their workspace role for shared ones, or "" without access
+	ProjectRole(ctx context.Context, project models.Project, userID uuid.UUID) (string, error)
+	// WorkspaceRole returns the user's role in the workspace, or "" if they are not a member
+	WorkspaceRole(ctx context.Context, workspaceID, userID uuid.UUID) (string, error)
+	Create(ctx context.Context, project *models.Project) error
+	// Update saves project, replacing previous, the project as it was loaded
+	Update(ctx context.Context, previous models.Project, project *models.Project) error
+	// Delete removes the project and its goals and moves its time entries to no project
+	// Returns ErrLocked, changing nothing, if any of those entries is locked
+	Delete(ctx context.Context, project models.Project) error
+}
+
+// ProfileTotals are a user's all-time tracked time
+type ProfileTotals struct {
+	Seconds  int64
+	Sessions int
+}
+
+// ProfileRepository stores profiles and reads the figures shown on them
+// Writes record the change in the audit log, using the audit context carried by ctx
+type ProfileRepository interface {
+	Find(ctx context.Context, userID uuid.UUID) (models.Profile, error)
+	// FindUser returns the auth user behind a profile
+	FindUser(ctx context.Context, userID uuid.UUID) (models.User, error)
+	Create(ctx context.Context, profile *models.Profile) error
+	// Update applies updates to the profile and returns the stored profile
+	// A new timezone also rebuilds the user's daily totals, which are bucketed by local date
+	Update(ctx context.Context, profile models.Profile, updates map[string]interface{}) (models.Profile, error)
+	Totals(ctx context.Context, userID uuid.UUID) (ProfileTotals, error)
+	// Streaks returns the user's streaks, counting days with all daily goals met if they have any
+	Streaks(ctx context.Context, profile models.Profile, now time.Time) (stats.Streaks, error)
+	// Rank returns the user's all-time rank among all users
+	Rank(ctx context.Context, userID uuid.UUID) (int, error)
+	LevelTiers(ctx context.Context) []models.LevelTier
+	// Achievements returns the user's unlocked achievements, most recent first
+	Achievements(ctx context.Context, userID uuid.UUID) ([]models.UserAchievement, error)
+}
+
+// AchievementRepository unlocks achievements
+type AchievementRepository interface {
+	// Evaluate unlocks the achievements earned by stopping the entry and returns them
+	Evaluate(ctx context.Context, entry models.TimeEntry) ([]models.Achievement, error)
+}
+
+// notFound translates GORM's missing record error
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
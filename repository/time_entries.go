@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"time-tracker/audit"
+	"time-tracker/models"
+	"time-tracker/periodlocks"
+	"time-tracker/stats"
+	"time-tracker/timesheets"
+	"time-tracker/workspaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type timeEntries struct {
+	db *gorm.DB
+}
+
+// NewTimeEntries returns a time entry repository backed by the database
+func NewTimeEntries(db *gorm.DB) TimeEntryRepository {
+	return &timeEntries{db: db}
+}
+
+func (r *timeEntries) FindOwned(ctx context.Context, userID, id uuid.UUID) (models.TimeEntry, error) {
+	var entry models.TimeEntry
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&entry).Error
+	return entry, notFound(err)
+}
+
+func (r *timeEntries) FindVisible(ctx context.Context, userID, id uuid.UUID) (models.TimeEntry, error) {
+	db := r.db.WithContext(ctx)
+
+	// Workspace admins can also see other members' entries on shared projects
+	var entry models.TimeEntry
+	err := db.Preload("Project").
+		Where("id = ? AND (user_id = ? OR project_id IN (?))", id, userID, workspaces.ManagedProjectIDs(db, userID)).
+		First(&entry).Error
+	return entry, notFound(err)
+}
+
+func (r *timeEntries) FindWithProject(ctx context.Context, id uuid.UUID) (models.TimeEntry, error) {
+	var entry models.TimeEntry
+	err := r.db.WithContext(ctx).Preload("Project").First(&entry, "id = ?", id).Error
+	return entry, notFound(err)
+}
+
+func (r *timeEntries) List(ctx context.Context, filter TimeEntryFilter, page Page) ([]models.TimeEntry, int64, error) {
+	db := r.db.WithContext(ctx)
+
+	query := db.Model(&models.TimeEntry{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.WorkspaceID != nil {
+		query = query.Where("project_id IN (?)", db.Model(&models.Project{}).Select("id").Where("workspace_id = ?", *filter.WorkspaceID))
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.TimeEntry
+	if err := query.Preload("Project").Order("created_at DESC").Limit(page.Limit).Offset(page.Offset()).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// Create creates the entry, updates the daily totals and audits the change together
+func (r *timeEntries) Create(ctx context.Context, entry *models.TimeEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, audit.FromContext(ctx), models.AuditEntityTimeEntry, entry.ID, nil, *entry); err != nil {
+			return err
+		}
+		return stats.AddEntry(tx, *entry)
+	})
+}
+
+// Update replaces the entry's old contribution to the daily totals with the new one
+func (r *timeEntries) Update(ctx context.Context, previous models.TimeEntry, entry *models.TimeEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := stats.RemoveEntry(tx, previous); err != nil {
+			return err
+		}
+		if err := tx.Save(entry).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, audit.FromContext(ctx), models.AuditEntityTimeEntry, entry.ID, previous, *entry); err != nil {
+			return err
+		}
+		return stats.AddEntry(tx, *entry)
+	})
+}
+
+// Delete removes the entry's contribution to the daily totals first:
+// soft-deleting sets DeletedAt, after which the entry no longer counts
+func (r *timeEntries) Delete(ctx context.Context, entry models.TimeEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := stats.RemoveEntry(tx, entry); err != nil {
+			return err
+		}
+		if err := audit.Record(tx, audit.FromContext(ctx), models.AuditEntityTimeEntry, entry.ID, entry, nil); err != nil {
+			return err
+		}
+		return tx.Delete(&entry).Error
+	})
+}
+
+func (r *timeEntries) PeriodLocked(ctx context.Context, userID uuid.UUID, t time.Time) (bool, error) {
+	return periodlocks.Locked(r.db.WithContext(ctx), userID, t)
+}
+
+func (r *timeEntries) InApprovedTimesheet(ctx context.Context, entry models.TimeEntry) (bool, error) {
+	return timesheets.Locked(r.db.WithContext(ctx), entry)
+}
+
+func (r *timeEntries) LockedUntil(ctx context.Context, entry models.TimeEntry) (time.Time, bool, error) {
+	db := r.db.WithContext(ctx)
+
+	periodEnd, periodLocked, err := periodlocks.LockedUntil(db, entry.UserID, entry.StartTime)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	weekEnd, weekLocked, err := timesheets.LockedUntil(db, entry)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if !weekLocked || (periodLocked && periodEnd.After(weekEnd)) {
+		return periodEnd, periodLocked, nil
+	}
+	return weekEnd, true, nil
+}
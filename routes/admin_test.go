@@ -0,0 +1,257 @@
+package routes_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+	"time-tracker/database"
+	"time-tracker/jobs"
+	"time-tracker/middleware"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestAdminLevelTiers(t *testing.T) {
+	h := newHarness(t)
+	admin := h.admin("Admin")
+
+	tiers := func() []models.LevelTier {
+		t.Helper()
+		rec := h.do(http.MethodGet, "/api/v1/admin/level-tiers", admin, nil)
+		h.expect(rec, http.StatusOK)
+		return decode[[]models.LevelTier](t, rec)
+	}
+
+	seeded := tiers()
+	if len(seeded) == 0 || seeded[0].MinHours != 0 {
+		t.Fatalf("expected the default tiers starting at zero hours, got %+v", seeded)
+	}
+
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/level-tiers", admin, gin.H{"name": "Ascended", "min_hours": 10000, "color": "red"}), http.StatusBadRequest)
+	h.expectError(h.do(http.MethodPost, "/api/v1/admin/level-tiers", admin, gin.H{"name": "Rookie", "min_hours": 0, "color": "#000000"}), http.StatusConflict, "A level tier with this threshold already exists")
+
+	rec := h.do(http.MethodPost, "/api/v1/admin/level-tiers", admin, gin.H{"name": "Ascended", "min_hours": 10000, "color": "#FF0000", "icon": "crown"})
+	h.expect(rec, http.StatusCreated)
+	ascended := decode[models.LevelTier](t, rec)
+	if list := tiers(); len(list) != len(seeded)+1 || list[len(list)-1].ID != ascended.ID {
+		t.Fatalf("expected Ascended as the highest tier, got %+v", list)
+	}
+
+	path := "/api/v1/admin/level-tiers/" + ascended.ID.String()
+	h.expectError(h.do(http.MethodPut, path, admin, gin.H{"name": "Ascended", "min_hours": 0, "color": "#FF0000"}), http.StatusConflict, "A level tier with this threshold already exists")
+	h.expectError(h.do(http.MethodPut, "/api/v1/admin/level-tiers/"+uuid.New().String(), admin, gin.H{"name": "Ghost", "min_hours": 1, "color": "#FF0000"}), http.StatusNotFound, "Level tier not found")
+	rec = h.do(http.MethodPut, path, admin, gin.H{"name": "Transcendent", "min_hours": 20000, "color": "#00FF00"})
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.LevelTier](t, rec); got.Name != "Transcendent" || got.MinHours != 20000 || got.Icon != "" {
+		t.Fatalf("expected the tier to be replaced, got %+v", got)
+	}
+
+	h.expect(h.do(http.MethodDelete, path, admin, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodDelete, path, admin, nil), http.StatusNotFound, "Level tier not found")
+	h.expectError(h.do(http.MethodDelete, "/api/v1/admin/level-tiers/nope", admin, nil), http.StatusBadRequest, "Invalid ID")
+	if list := tiers(); len(list) != len(seeded) {
+		t.Fatalf("expected only the default tiers, got %+v", list)
+	}
+}
+
+func TestAdminLevelTiersEmpty(t *testing.T) {
+	h := newHarness(t)
+	admin := h.admin("Admin")
+	alice := h.signUp("Alice")
+
+	rec := h.do(http.MethodGet, "/api/v1/admin/level-tiers", admin, nil)
+	h.expect(rec, http.StatusOK)
+	for _, tier := range decode[[]models.LevelTier](t, rec) {
+		h.expect(h.do(http.MethodDelete, "/api/v1/admin/level-tiers/"+tier.ID.String(), admin, nil), http.StatusOK)
+	}
+
+	// Without tiers the default ones apply, and the next startup stores them again
+	rec = h.do(http.MethodGet, "/api/v1/profile", alice, nil)
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.UserResponse](t, rec); got.Level != models.DefaultLevelTiers[0].Name {
+		t.Fatalf("expected the lowest default level, got %q", got.Level)
+	}
+	if err := database.Seed(h.db); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	rec = h.do(http.MethodGet, "/api/v1/admin/level-tiers", admin, nil)
+	h.expect(rec, http.StatusOK)
+	if list := decode[[]models.LevelTier](t, rec); len(list) != len(models.DefaultLevelTiers) {
+		t.Fatalf("expected the default tiers to be seeded again, got %+v", list)
+	}
+}
+
+func TestAdminPeriodLocks(t *testing.T) {
+	h := newHarness(t)
+	admin := h.admin("Admin")
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+
+	h.expectError(h.doUnchecked(http.MethodPost, "/api/v1/admin/period-locks", admin, gin.H{"locked_before": "last month"}), http.StatusBadRequest, "Invalid locked_before format (use YYYY-MM-DD)")
+
+	rec := h.do(http.MethodPost, "/api/v1/admin/period-locks", admin, gin.H{"locked_before": "2024-01-01"})
+	h.expect(rec, http.StatusCreated)
+	global := decode[models.PeriodLockResponse](t, rec)
+	if global.UserID != nil || global.LockedBefore != "2024-01-01" || global.CreatedBy != admin.ID {
+		t.Fatalf("unexpected lock %+v", global)
+	}
+	rec = h.do(http.MethodPost, "/api/v1/admin/period-locks", admin, gin.H{"locked_before": "2024-06-01", "user_id": alice.ID})
+	h.expect(rec, http.StatusCreated)
+	personal := decode[models.PeriodLockResponse](t, rec)
+
+	locks := func(query string) []models.PeriodLockResponse {
+		t.Helper()
+		rec := h.do(http.MethodGet, "/api/v1/admin/period-locks"+query, admin, nil)
+		h.expect(rec, http.StatusOK)
+		return decode[[]models.PeriodLockResponse](t, rec)
+	}
+
+	if list := locks(""); len(list) != 2 || list[0].ID != personal.ID {
+		t.Fatalf("expected both locks, latest first, got %+v", list)
+	}
+	if list := locks("?user_id=" + bob.ID.String()); len(list) != 1 || list[0].ID != global.ID {
+		t.Fatalf("expected only the global lock for Bob, got %+v", list)
+	}
+	h.expectError(h.do(http.MethodGet, "/api/v1/admin/period-locks?user_id=nope", admin, nil), http.StatusBadRequest, "Invalid user ID")
+
+	path := "/api/v1/admin/period-locks/" + personal.ID.String()
+	h.expect(h.do(http.MethodDelete, path, admin, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodDelete, path, admin, nil), http.StatusNotFound, "Period lock not found")
+	if list := locks("?user_id=" + alice.ID.String()); len(list) != 1 || list[0].ID != global.ID {
+		t.Fatalf("expected only the global lock for Alice, got %+v", list)
+	}
+}
+
+func TestAdminUsers(t *testing.T) {
+	h := newHarness(t)
+	admin := h.admin("Admin")
+	alice := h.signUp("Alice")
+	h.signUp("Alina")
+	bob := h.signUp("Bob")
+	nobody := h.user("Nobody")
+
+	rec := h.do(http.MethodGet, "/api/v1/admin/users?q=ALI&limit=1", admin, nil)
+	h.expect(rec, http.StatusOK)
+	users := decode[models.PaginatedAdminUsersResponse](t, rec)
+	if users.Total != 2 || users.TotalPages != 2 || len(users.Data) != 1 {
+		t.Fatalf("expected the first of two matching users, got %+v", users)
+	}
+
+	h.track(alice, nil, 2*time.Hour)
+	h.track(alice, nil, time.Hour)
+
+	entries := "/api/v1/admin/users/" + alice.ID.String() + "/time-entries"
+	rec = h.do(http.MethodGet, entries, admin, nil)
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.PaginatedTimeEntriesResponse](t, rec); got.Total != 2 || got.Data[0].Duration != 3600 {
+		t.Fatalf("expected Alice's two entries, newest first, got %+v", got)
+	}
+	h.expectError(h.do(http.MethodGet, "/api/v1/admin/users/"+nobody.ID.String()+"/time-entries", admin, nil), http.StatusNotFound, "User not found")
+	h.expectError(h.do(http.MethodGet, "/api/v1/admin/users/nope/time-entries", admin, nil), http.StatusBadRequest, "Invalid ID")
+
+	totalHours := func(u testUser) float32 {
+		t.Helper()
+		rec := h.do(http.MethodGet, "/api/v1/profile", u, nil)
+		h.expect(rec, http.StatusOK)
+		return decode[models.UserResponse](t, rec).TotalHours
+	}
+
+	// Lose the daily totals, then rebuild them from the time entries
+	if err := h.db.Exec("DELETE FROM daily_user_totals").Error; err != nil {
+		t.Fatalf("delete daily totals: %v", err)
+	}
+	if got := totalHours(alice); got != 0 {
+		t.Fatalf("expected no hours without daily totals, got %v", got)
+	}
+
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/users/"+bob.ID.String()+"/stats/recalculate", admin, nil), http.StatusOK)
+	if got := totalHours(alice); got != 0 {
+		t.Fatalf("expected Bob's recalculation to leave Alice alone, got %v", got)
+	}
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/users/"+alice.ID.String()+"/stats/recalculate", admin, nil), http.StatusOK)
+	if got := totalHours(alice); got != 3 {
+		t.Fatalf("expected three hours after the recalculation, got %v", got)
+	}
+
+	if err := h.db.Exec("DELETE FROM daily_user_totals").Error; err != nil {
+		t.Fatalf("delete daily totals: %v", err)
+	}
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/stats/recalculate", admin, nil), http.StatusAccepted)
+	// The recalculation runs in the background
+	for deadline := time.Now().Add(5 * time.Second); jobs.StatsRebuildRunning(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the recalculation to finish")
+		}
+	}
+	if got := totalHours(alice); got != 3 {
+		t.Fatalf("expected three hours after recalculating everyone, got %v", got)
+	}
+
+	// On startup, empty daily totals are filled from the time entries
+	if err := h.db.Exec("DELETE FROM daily_user_totals").Error; err != nil {
+		t.Fatalf("delete daily totals: %v", err)
+	}
+	if err := jobs.BackfillStats(h.db); err != nil {
+		t.Fatalf("backfill statistics: %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); jobs.StatsRebuildRunning(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the backfill to finish")
+		}
+	}
+	if got := totalHours(alice); got != 3 {
+		t.Fatalf("expected three hours after the backfill, got %v", got)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	h := newHarness(t)
+	admin := h.admin("Admin")
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+
+	rec := h.do(http.MethodPost, "/api/v1/projects", alice, gin.H{"name": "Website"})
+	h.expect(rec, http.StatusCreated)
+	project := decode[models.ProjectResponse](t, rec)
+	requestID := rec.Header().Get(middleware.RequestIDHeader)
+	h.track(bob, nil, time.Hour)
+
+	auditLog := func(query string) models.PaginatedAuditLogResponse {
+		t.Helper()
+		rec := h.do(http.MethodGet, "/api/v1/audit"+query, admin, nil)
+		h.expect(rec, http.StatusOK)
+		return decode[models.PaginatedAuditLogResponse](t, rec)
+	}
+
+	created := auditLog("?request_id=" + requestID)
+	if created.Total != 1 {
+		t.Fatalf("expected one entry for the request, got %+v", created)
+	}
+	if got := created.Data[0]; got.EntityID != project.ID || got.EntityType != models.AuditEntityProject ||
+		got.Action != models.AuditActionCreate || got.ActorID == nil || *got.ActorID != alice.ID {
+		t.Fatalf("unexpected audit entry %+v", got)
+	}
+
+	// Tracking creates the entry and then sets its end time
+	entries := auditLog("?entity_type=" + models.AuditEntityTimeEntry + "&actor_id=" + bob.ID.String())
+	if entries.Total != 2 || entries.Data[0].Action != models.AuditActionUpdate || entries.Data[1].Action != models.AuditActionCreate {
+		t.Fatalf("expected Bob's create and update, got %+v", entries)
+	}
+	if got := auditLog("?entity_id=" + project.ID.String() + "&action=" + models.AuditActionDelete); got.Total != 0 {
+		t.Fatalf("expected no deletions, got %+v", got)
+	}
+
+	if page := auditLog("?limit=1&page=2"); len(page.Data) != 1 || page.Total < 3 || page.Page != 2 {
+		t.Fatalf("expected the second page of one entry, got %+v", page)
+	}
+	future := url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339))
+	if got := auditLog("?from=" + future); got.Total != 0 {
+		t.Fatalf("expected nothing from the future, got %+v", got)
+	}
+
+	h.expectError(h.do(http.MethodGet, "/api/v1/audit?actor_id=nope", admin, nil), http.StatusBadRequest, "Invalid actor_id")
+	h.expectError(h.doUnchecked(http.MethodGet, "/api/v1/audit?from=yesterday", admin, nil), http.StatusBadRequest, "Invalid from time format")
+}
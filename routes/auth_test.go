@@ -0,0 +1,215 @@
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"time-tracker/config"
+	"time-tracker/models"
+	"time-tracker/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestHealth(t *testing.T) {
+	h := newHarness(t)
+
+	rec := h.do(http.MethodGet, "/health", testUser{}, nil)
+	h.expect(rec, http.StatusOK)
+	if got := decode[gin.H](t, rec)["status"]; got != "ok" {
+		t.Fatalf("expected status ok, got %v", got)
+	}
+	if rec.Header().Get("X-Request-ID") == "" {
+		t.Fatal("expected a request ID header")
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+
+	t.Run("missing header", func(t *testing.T) {
+		h.expectError(h.do(http.MethodGet, "/api/v1/time-entries", testUser{}, nil), http.StatusUnauthorized, "Authorization header required")
+	})
+
+	t.Run("not a bearer token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/time-entries", nil)
+		req.Header.Set("Authorization", "Basic "+alice.Token)
+		h.expectError(h.send(req, ""), http.StatusUnauthorized, "Invalid authorization header format")
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		forged := alice
+		forged.Token = alice.Token[:len(alice.Token)-4] + "abcd"
+		h.expectError(h.do(http.MethodGet, "/api/v1/time-entries", forged, nil), http.StatusUnauthorized, "Invalid or expired token")
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		expired := alice
+		expired.Token = signToken(t, alice.ID, alice.Email, "authenticated", time.Now().Add(-time.Minute))
+		h.expectError(h.do(http.MethodGet, "/api/v1/time-entries", expired, nil), http.StatusUnauthorized, "Invalid or expired token")
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		h.expect(h.do(http.MethodGet, "/api/v1/time-entries", alice, nil), http.StatusOK)
+	})
+
+	t.Run("admin routes need an admin role", func(t *testing.T) {
+		h.expectError(h.do(http.MethodGet, "/api/v1/admin/users", alice, nil), http.StatusForbidden, "Insufficient role")
+		h.expectError(h.do(http.MethodGet, "/api/v1/audit", alice, nil), http.StatusForbidden, "Insufficient role")
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+	h := newHarness(t, func(cfg *config.Config) {
+		cfg.RateLimit.Groups["projects"] = ratelimit.Limit{Requests: 2, Period: time.Minute}
+	})
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+
+	for i := 0; i < 2; i++ {
+		rec := h.do(http.MethodGet, "/api/v1/projects", alice, nil)
+		h.expect(rec, http.StatusOK)
+		if got := rec.Header().Get("RateLimit-Remaining"); got != []string{"1", "0"}[i] {
+			t.Fatalf("request %d: expected %d remaining, got %q", i+1, 1-i, got)
+		}
+	}
+
+	rec := h.do(http.MethodGet, "/api/v1/projects", alice, nil)
+	h.expectError(rec, http.StatusTooManyRequests, "Too many requests")
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("expected a Retry-After header")
+	}
+
+	// Limits are kept per user and per route group
+	h.expect(h.do(http.MethodGet, "/api/v1/projects", bob, nil), http.StatusOK)
+	h.expect(h.do(http.MethodGet, "/api/v1/time-entries", alice, nil), http.StatusOK)
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	limitIP := func(cfg *config.Config) {
+		cfg.RateLimit.Groups["ip"] = ratelimit.Limit{Requests: 2, Period: time.Minute}
+	}
+
+	// send makes an anonymous request claiming to come from the forwarded address
+	send := func(h *harness, forwardedFor string) *httptest.ResponseRecorder {
+		req := h.request(http.MethodGet, "/api/v1/projects", nil)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		return h.send(req, "")
+	}
+
+	t.Run("spoofed", func(t *testing.T) {
+		h := newHarness(t, limitIP)
+		h.expect(send(h, "203.0.113.1"), http.StatusUnauthorized)
+		h.expect(send(h, "203.0.113.2"), http.StatusUnauthorized)
+		h.expectError(send(h, "203.0.113.3"), http.StatusTooManyRequests, "Too many requests")
+	})
+
+	t.Run("trusted proxy", func(t *testing.T) {
+		// httptest requests come from 192.0.2.1
+		h := newHarness(t, limitIP, func(cfg *config.Config) {
+			cfg.Server.TrustedProxies = []string{"192.0.2.0/24"}
+		})
+		h.expect(send(h, "203.0.113.1"), http.StatusUnauthorized)
+		h.expect(send(h, "203.0.113.1"), http.StatusUnauthorized)
+		h.expectError(send(h, "203.0.113.1"), http.StatusTooManyRequests, "Too many requests")
+		h.expect(send(h, "203.0.113.2"), http.StatusUnauthorized)
+	})
+}
+
+func TestPersonalAccessTokens(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+
+	h.expect(h.do(http.MethodPost, "/api/v1/tokens", alice, gin.H{"name": "CLI", "scopes": []string{"admin"}}), http.StatusBadRequest)
+
+	rec := h.do(http.MethodPost, "/api/v1/tokens", alice, gin.H{
+		"name":            "CLI",
+		"scopes":          []string{models.ScopeTimeEntriesRead, models.ScopeTimeEntriesRead},
+		"expires_in_days": 30,
+	})
+	h.expect(rec, http.StatusCreated)
+	created := decode[models.PersonalAccessTokenResponse](t, rec)
+	if created.Token == "" || created.ExpiresAt == nil {
+		t.Fatalf("expected a token with an expiry, got %+v", created)
+	}
+	if len(created.Scopes) != 1 || created.Scopes[0] != models.ScopeTimeEntriesRead {
+		t.Fatalf("expected the scope once, got %v", created.Scopes)
+	}
+	pat := testUser{ID: alice.ID, Token: created.Token}
+
+	t.Run("scopes", func(t *testing.T) {
+		h.expect(h.do(http.MethodGet, "/api/v1/time-entries", pat, nil), http.StatusOK)
+		h.expectError(h.do(http.MethodPost, "/api/v1/time-entries", pat, gin.H{}), http.StatusForbidden, "Token is missing the time_entries:write scope")
+		h.expectError(h.do(http.MethodGet, "/api/v1/projects", pat, nil), http.StatusForbidden, "Token is missing the projects:read scope")
+		h.expectError(h.do(http.MethodGet, "/api/v1/leaderboard", pat, nil), http.StatusForbidden, "Token is missing the leaderboard:read scope")
+		h.expectError(h.do(http.MethodGet, "/api/v1/achievements", pat, nil), http.StatusForbidden, "Token is missing the profile:read scope")
+	})
+
+	t.Run("tokens cannot manage tokens", func(t *testing.T) {
+		const message = "Personal access tokens cannot manage tokens"
+		h.expectError(h.do(http.MethodGet, "/api/v1/tokens", pat, nil), http.StatusForbidden, message)
+		h.expectError(h.do(http.MethodPost, "/api/v1/tokens", pat, gin.H{"name": "Wider", "scopes": []string{models.ScopeProjectsWrite}}), http.StatusForbidden, message)
+		h.expectError(h.do(http.MethodDelete, "/api/v1/tokens/"+created.ID.String(), pat, nil), http.StatusForbidden, message)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		rec := h.do(http.MethodGet, "/api/v1/tokens", alice, nil)
+		h.expect(rec, http.StatusOK)
+		list := decode[[]models.PersonalAccessTokenResponse](t, rec)
+		if len(list) != 1 || list[0].ID != created.ID || list[0].Token != "" {
+			t.Fatalf("expected the token without its secret, got %+v", list)
+		}
+		if list[0].LastUsedAt == nil {
+			t.Fatal("expected the token's last use to be recorded")
+		}
+
+		bob := h.signUp("Bob")
+		rec = h.do(http.MethodGet, "/api/v1/tokens", bob, nil)
+		h.expect(rec, http.StatusOK)
+		if list := decode[[]models.PersonalAccessTokenResponse](t, rec); len(list) != 0 {
+			t.Fatalf("expected no tokens for another user, got %d", len(list))
+		}
+		h.expectError(h.do(http.MethodDelete, "/api/v1/tokens/"+created.ID.String(), bob, nil), http.StatusNotFound, "Token not found")
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		h.expectError(h.do(http.MethodDelete, "/api/v1/tokens/not-a-uuid", alice, nil), http.StatusBadRequest, "Invalid ID")
+		h.expect(h.do(http.MethodDelete, "/api/v1/tokens/"+created.ID.String(), alice, nil), http.StatusOK)
+		h.expect(h.do(http.MethodGet, "/api/v1/time-entries", pat, nil), http.StatusUnauthorized)
+	})
+}
+
+func TestDevToken(t *testing.T) {
+	h := newHarness(t)
+	h.expect(h.do(http.MethodPost, "/api/v1/dev/token", testUser{}, nil), http.StatusNotFound)
+
+	h = newHarness(t, func(cfg *config.Config) {
+		cfg.Auth.Mode = config.AuthModeInsecureDev
+		cfg.Auth.JWT.Secret = ""
+	})
+
+	userID := uuid.New()
+	rec := h.do(http.MethodPost, "/api/v1/dev/token", testUser{}, gin.H{"user_id": userID, "email": "dev@example.com", "ttl_minutes": 5})
+	h.expect(rec, http.StatusCreated)
+	issued := decode[models.DevTokenResponse](t, rec)
+	if issued.UserID != userID || issued.TokenType != "Bearer" {
+		t.Fatalf("unexpected token %+v", issued)
+	}
+	if remaining := time.Until(issued.ExpiresAt); remaining <= 0 || remaining > 5*time.Minute {
+		t.Fatalf("expected the token to expire in 5 minutes, got %s", remaining)
+	}
+
+	dev := testUser{ID: userID, Token: issued.AccessToken}
+	h.expect(h.do(http.MethodGet, "/api/v1/time-entries", dev, nil), http.StatusOK)
+
+	// Only development tokens are accepted, not ones signed with a real key
+	signed := testUser{ID: userID, Token: signToken(t, userID, "dev@example.com", "service_role", time.Now().Add(time.Hour))}
+	h.expectError(h.do(http.MethodGet, "/api/v1/time-entries", signed, nil), http.StatusUnauthorized, "Invalid or expired token")
+
+	// and development tokens are not accepted outside development mode
+	h = newHarness(t)
+	h.expectError(h.do(http.MethodGet, "/api/v1/time-entries", dev, nil), http.StatusUnauthorized, "Invalid or expired token")
+}
@@ -0,0 +1,144 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// befriend makes the two users friends through a request and its acceptance
+func (h *harness) befriend(a, b testUser) {
+	h.t.Helper()
+
+	rec := h.do(http.MethodPost, "/api/v1/friends/requests", a, gin.H{"user_id": b.ID})
+	h.expect(rec, http.StatusCreated)
+	request := decode[models.FriendResponse](h.t, rec)
+	h.expect(h.do(http.MethodPost, "/api/v1/friends/requests/"+request.FriendshipID.String()+"/accept", b, nil), http.StatusOK)
+}
+
+// friends returns the user's friends
+func (h *harness) friends(u testUser) []models.FriendResponse {
+	h.t.Helper()
+
+	rec := h.do(http.MethodGet, "/api/v1/friends", u, nil)
+	h.expect(rec, http.StatusOK)
+	return decode[[]models.FriendResponse](h.t, rec)
+}
+
+func TestFriendRequests(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+	carol := h.signUp("Carol")
+
+	h.expectError(h.do(http.MethodPost, "/api/v1/friends/requests", alice, gin.H{"user_id": alice.ID}), http.StatusBadRequest, "Cannot send a friend request to yourself")
+	h.expectError(h.do(http.MethodPost, "/api/v1/friends/requests", alice, gin.H{"user_id": uuid.New()}), http.StatusNotFound, "User not found")
+
+	rec := h.do(http.MethodPost, "/api/v1/friends/requests", alice, gin.H{"user_id": bob.ID})
+	h.expect(rec, http.StatusCreated)
+	request := decode[models.FriendResponse](t, rec)
+	if request.UserID != bob.ID || request.Name != "Bob" {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	h.expectError(h.do(http.MethodPost, "/api/v1/friends/requests", alice, gin.H{"user_id": bob.ID}), http.StatusConflict, "Friend request already sent")
+
+	// A second row for the pair cannot slip in the other way round either
+	if err := h.db.Create(&models.Friendship{RequesterID: bob.ID, AddresseeID: alice.ID, Status: models.FriendshipPending}).Error; err == nil {
+		t.Fatal("expected a second friendship between Alice and Bob to be rejected")
+	}
+
+	rec = h.do(http.MethodGet, "/api/v1/friends/requests", bob, nil)
+	h.expect(rec, http.StatusOK)
+	requests := decode[models.FriendRequestsResponse](t, rec)
+	if len(requests.Incoming) != 1 || requests.Incoming[0].UserID != alice.ID || len(requests.Outgoing) != 0 {
+		t.Fatalf("expected Alice's incoming request, got %+v", requests)
+	}
+
+	accept := "/api/v1/friends/requests/" + request.FriendshipID.String() + "/accept"
+	h.expectError(h.do(http.MethodPost, accept, alice, nil), http.StatusNotFound, "Friend request not found")
+	h.expectError(h.do(http.MethodPost, accept, carol, nil), http.StatusNotFound, "Friend request not found")
+	rec = h.do(http.MethodPost, accept, bob, nil)
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.FriendResponse](t, rec); got.UserID != alice.ID {
+		t.Fatalf("expected Alice as a friend, got %+v", got)
+	}
+	h.expectError(h.do(http.MethodPost, "/api/v1/friends/requests", bob, gin.H{"user_id": alice.ID}), http.StatusConflict, "Already friends")
+
+	if list := h.friends(alice); len(list) != 1 || list[0].UserID != bob.ID {
+		t.Fatalf("expected Bob as Alice's friend, got %+v", list)
+	}
+
+	// Crossed requests become a friendship
+	h.expect(h.do(http.MethodPost, "/api/v1/friends/requests", carol, gin.H{"user_id": alice.ID}), http.StatusCreated)
+	h.expect(h.do(http.MethodPost, "/api/v1/friends/requests", alice, gin.H{"user_id": carol.ID}), http.StatusOK)
+	if list := h.friends(carol); len(list) != 1 || list[0].UserID != alice.ID {
+		t.Fatalf("expected Alice as Carol's friend, got %+v", list)
+	}
+
+	// Requests can be withdrawn by the sender or declined by the recipient, but not by others
+	rec = h.do(http.MethodPost, "/api/v1/friends/requests", bob, gin.H{"user_id": carol.ID})
+	h.expect(rec, http.StatusCreated)
+	pending := "/api/v1/friends/requests/" + decode[models.FriendResponse](t, rec).FriendshipID.String()
+	h.expectError(h.do(http.MethodDelete, pending, alice, nil), http.StatusNotFound, "Friend request not found")
+	h.expect(h.do(http.MethodDelete, pending, carol, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodDelete, pending, bob, nil), http.StatusNotFound, "Friend request not found")
+	h.expectError(h.do(http.MethodDelete, "/api/v1/friends/requests/nope", bob, nil), http.StatusBadRequest, "Invalid ID")
+
+	// Removing friends
+	h.expectError(h.do(http.MethodDelete, "/api/v1/friends/"+carol.ID.String(), bob, nil), http.StatusNotFound, "Friend not found")
+	h.expect(h.do(http.MethodDelete, "/api/v1/friends/"+alice.ID.String(), bob, nil), http.StatusOK)
+	if list := h.friends(alice); len(list) != 1 || list[0].UserID != carol.ID {
+		t.Fatalf("expected only Carol as Alice's friend, got %+v", list)
+	}
+}
+
+func TestBlockedUsers(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+
+	h.befriend(alice, bob)
+
+	h.expectError(h.do(http.MethodPost, "/api/v1/friends/"+alice.ID.String()+"/block", alice, nil), http.StatusBadRequest, "Cannot block yourself")
+	h.expectError(h.do(http.MethodPost, "/api/v1/friends/nope/block", alice, nil), http.StatusBadRequest, "Invalid user ID")
+
+	// Blocking ends the friendship and stops new requests either way
+	h.expect(h.do(http.MethodPost, "/api/v1/friends/"+bob.ID.String()+"/block", alice, nil), http.StatusOK)
+	if list := h.friends(bob); len(list) != 0 {
+		t.Fatalf("expected the friendship to end, got %+v", list)
+	}
+	const message = "Cannot send a friend request to this user"
+	h.expectError(h.do(http.MethodPost, "/api/v1/friends/requests", bob, gin.H{"user_id": alice.ID}), http.StatusForbidden, message)
+	h.expectError(h.do(http.MethodPost, "/api/v1/friends/requests", alice, gin.H{"user_id": bob.ID}), http.StatusForbidden, message)
+
+	rec := h.do(http.MethodGet, "/api/v1/friends/blocked", alice, nil)
+	h.expect(rec, http.StatusOK)
+	if list := decode[[]models.FriendResponse](t, rec); len(list) != 1 || list[0].UserID != bob.ID {
+		t.Fatalf("expected Bob to be blocked, got %+v", list)
+	}
+	rec = h.do(http.MethodGet, "/api/v1/friends/blocked", bob, nil)
+	h.expect(rec, http.StatusOK)
+	if list := decode[[]models.FriendResponse](t, rec); len(list) != 0 {
+		t.Fatalf("expected Bob to block nobody, got %+v", list)
+	}
+
+	// Only the user who blocked can unblock
+	h.expectError(h.do(http.MethodDelete, "/api/v1/friends/"+alice.ID.String()+"/block", bob, nil), http.StatusNotFound, "Blocked user not found")
+	h.expect(h.do(http.MethodDelete, "/api/v1/friends/"+bob.ID.String()+"/block", alice, nil), http.StatusOK)
+	h.expect(h.do(http.MethodPost, "/api/v1/friends/requests", bob, gin.H{"user_id": alice.ID}), http.StatusCreated)
+
+	// Blocks placed by both users are independent: lifting one leaves the other in place
+	h.expect(h.do(http.MethodPost, "/api/v1/friends/"+bob.ID.String()+"/block", alice, nil), http.StatusOK)
+	h.expect(h.do(http.MethodPost, "/api/v1/friends/"+alice.ID.String()+"/block", bob, nil), http.StatusOK)
+	h.expect(h.do(http.MethodPost, "/api/v1/friends/"+bob.ID.String()+"/block", alice, nil), http.StatusOK)
+	h.expect(h.do(http.MethodDelete, "/api/v1/friends/"+bob.ID.String()+"/block", alice, nil), http.StatusOK)
+	rec = h.do(http.MethodGet, "/api/v1/friends/blocked", bob, nil)
+	h.expect(rec, http.StatusOK)
+	if list := decode[[]models.FriendResponse](t, rec); len(list) != 1 || list[0].UserID != alice.ID {
+		t.Fatalf("expected Bob's block on Alice to remain, got %+v", list)
+	}
+	h.expectError(h.do(http.MethodPost, "/api/v1/friends/requests", alice, gin.H{"user_id": bob.ID}), http.StatusForbidden, message)
+}
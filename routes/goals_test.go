@@ -0,0 +1,82 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGoals(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+
+	h.expect(h.do(http.MethodPost, "/api/v1/goals", alice, gin.H{"period": "monthly", "target_minutes": 60}), http.StatusBadRequest)
+	h.expect(h.do(http.MethodPost, "/api/v1/goals", alice, gin.H{"period": "daily", "target_minutes": 0}), http.StatusBadRequest)
+
+	secret := h.createProject(bob, gin.H{"name": "Secret"})
+	h.expectError(h.do(http.MethodPost, "/api/v1/goals", alice, gin.H{"period": "daily", "target_minutes": 60, "project_id": secret.ID}), http.StatusBadRequest, "Project not found")
+
+	rec := h.do(http.MethodPost, "/api/v1/goals", alice, gin.H{"period": "daily", "target_minutes": 60})
+	h.expect(rec, http.StatusCreated)
+	daily := decode[models.GoalResponse](t, rec)
+	if daily.Period != "daily" || len(daily.Weekdays) != 7 {
+		t.Fatalf("expected a daily goal for every day, got %+v", daily)
+	}
+
+	project := h.createProject(alice, gin.H{"name": "Website"})
+	rec = h.do(http.MethodPost, "/api/v1/goals", alice, gin.H{"period": "weekly", "target_minutes": 600, "project_id": project.ID})
+	h.expect(rec, http.StatusCreated)
+	weekly := decode[models.GoalResponse](t, rec)
+	if weekly.Project == nil || weekly.Project.ID != project.ID {
+		t.Fatalf("expected a goal on project %s, got %+v", project.ID, weekly.Project)
+	}
+
+	rec = h.do(http.MethodGet, "/api/v1/goals", alice, nil)
+	h.expect(rec, http.StatusOK)
+	if list := decode[[]models.GoalResponse](t, rec); len(list) != 2 || list[0].ID != daily.ID {
+		t.Fatalf("expected both goals, oldest first, got %+v", list)
+	}
+	rec = h.do(http.MethodGet, "/api/v1/goals", bob, nil)
+	h.expect(rec, http.StatusOK)
+	if list := decode[[]models.GoalResponse](t, rec); len(list) != 0 {
+		t.Fatalf("expected no goals for Bob, got %d", len(list))
+	}
+
+	// An hour on the General project completes the daily goal
+	h.track(alice, nil, time.Hour)
+
+	rec = h.do(http.MethodGet, "/api/v1/goals/progress", alice, nil)
+	h.expect(rec, http.StatusOK)
+	progress := decode[models.GoalProgressResponse](t, rec)
+	if len(progress.Daily) != 1 || !progress.Daily[0].Completed || progress.Daily[0].TrackedMinutes != 60 {
+		t.Fatalf("expected the daily goal to be completed, got %+v", progress.Daily)
+	}
+	if len(progress.Weekly) != 1 || progress.Weekly[0].Completed || progress.Weekly[0].TrackedMinutes != 0 {
+		t.Fatalf("expected no progress on the project goal, got %+v", progress.Weekly)
+	}
+
+	rec = h.do(http.MethodGet, "/api/v1/goals/history?days=3", alice, nil)
+	h.expect(rec, http.StatusOK)
+	history := decode[[]models.GoalDay](t, rec)
+	if len(history) != 3 || !history[2].Completed || history[2].Date != time.Now().UTC().Format("2006-01-02") {
+		t.Fatalf("expected today's goal to be met, got %+v", history)
+	}
+
+	path := "/api/v1/goals/" + weekly.ID.String()
+	h.expectError(h.do(http.MethodPut, path, bob, gin.H{"target_minutes": 1}), http.StatusNotFound, "Goal not found")
+	h.expect(h.do(http.MethodPut, path, alice, gin.H{"weekdays": []int{}}), http.StatusBadRequest)
+	rec = h.do(http.MethodPut, path, alice, gin.H{"target_minutes": 300})
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.GoalResponse](t, rec); got.TargetMinutes != 300 || got.Project == nil {
+		t.Fatalf("expected only the target to change, got %+v", got)
+	}
+
+	h.expectError(h.do(http.MethodDelete, path, bob, nil), http.StatusNotFound, "Goal not found")
+	h.expectError(h.do(http.MethodDelete, "/api/v1/goals/not-a-uuid", alice, nil), http.StatusBadRequest, "Invalid ID")
+	h.expect(h.do(http.MethodDelete, path, alice, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodDelete, path, alice, nil), http.StatusNotFound, "Goal not found")
+}
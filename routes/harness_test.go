@@ -0,0 +1,470 @@
+package routes_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"time-tracker/apispec"
+	"time-tracker/config"
+	"time-tracker/database"
+	"time-tracker/levels"
+	"time-tracker/middleware"
+	"time-tracker/models"
+	"time-tracker/ratelimit"
+	"time-tracker/routes"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// testJWTSecret signs the HS256 access tokens minted by the tests
+const testJWTSecret = "integration-test-secret"
+
+// sqliteDriver is an SQLite driver that mimics the bits of Supabase's Postgres the app relies on:
+// the uuid_generate_v4() column default, LEAST and GREATEST, and the public and auth schemas named by
+// models.Profile and models.User
+const sqliteDriver = "sqlite3_time_tracker"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			if err := conn.RegisterFunc("uuid_generate_v4", uuid.NewString, false); err != nil {
+				return err
+			}
+			least := func(a, b string) string { return min(a, b) }
+			greatest := func(a, b string) string { return max(a, b) }
+			if err := conn.RegisterFunc("least", least, true); err != nil {
+				return err
+			}
+			if err := conn.RegisterFunc("greatest", greatest, true); err != nil {
+				return err
+			}
+			// Every connection attaches the schemas as databases stored next to the main one
+			for _, schema := range []string{"public", "auth"} {
+				attach := fmt.Sprintf(`ATTACH DATABASE (SELECT file || '.%[1]s' FROM pragma_database_list WHERE name = 'main') AS %[1]s`, schema)
+				if _, err := conn.Exec(attach, nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// spec is openapi.yaml, which every request and response in the tests must match
+var spec *apispec.Spec
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	log.SetOutput(io.Discard)
+
+	var err error
+	if spec, err = apispec.Load("../openapi.yaml"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Timestamps are compared as text in SQLite, so keep them all in one zone
+	time.Local = time.UTC
+
+	code := m.Run()
+	if code == 0 && flag.Lookup("test.run").Value.String() == "" {
+		if missing := coverage.missing(); len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "routes without an integration test:\n  %s\n", strings.Join(missing, "\n  "))
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// harness serves the API built by routes.SetupRoutes from a fresh SQLite database
+type harness struct {
+	t         *testing.T
+	db        *gorm.DB
+	engine    *gin.Engine
+	unchecked *gin.Engine // Same API with OpenAPI violations only logged
+	storage   *memoryStorage
+}
+
+// newHarness builds the API for one test; options adjust the configuration first
+// Rate limits are off unless an option turns them on, and OpenAPI validation is strict for requests and responses
+func newHarness(t *testing.T, options ...func(*config.Config)) *harness {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Env = "test"
+	cfg.Auth.Mode = config.AuthModeVerify
+	cfg.Auth.JWT.Secret = testJWTSecret
+	cfg.RateLimit.Default = ratelimit.Limit{}
+	cfg.RateLimit.Groups = map[string]ratelimit.Limit{}
+	cfg.OpenAPI.Mode = config.OpenAPIModeStrict
+	cfg.OpenAPI.ValidateResponses = true
+	for _, option := range options {
+		option(cfg)
+	}
+
+	db := openDatabase(t)
+	levels.Invalidate()
+	t.Cleanup(levels.Invalidate)
+
+	h := &harness{t: t, db: db, storage: newMemoryStorage()}
+	deps := routes.Dependencies{DB: db, Storage: h.storage, Verifier: middleware.NewVerifier(cfg.Auth.JWT), Spec: spec}
+	h.engine = routes.SetupRoutes(cfg, deps)
+	coverage.register(h.engine)
+
+	lenient := *cfg
+	lenient.OpenAPI.Mode = config.OpenAPIModeLog
+	h.unchecked = routes.SetupRoutes(&lenient, deps)
+	return h
+}
+
+// openDatabase creates the schema in a temporary SQLite database
+func openDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := "file:" + filepath.Join(t.TempDir(), "time-tracker.db") + "?_busy_timeout=5000&_journal_mode=WAL"
+	db, err := gorm.Open(sqliteDialector{sqlite.New(sqlite.Config{DriverName: sqliteDriver, DSN: dsn}).(*sqlite.Dialector)}, &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+		Logger:                                   logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	tables := append([]interface{}{&models.User{}, &models.Profile{}}, database.Models...)
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := normalizeDates(db, tables); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := database.Seed(db); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return db
+}
+
+// sqliteDialector wraps column defaults that call functions in parentheses, as SQLite requires
+type sqliteDialector struct {
+	*sqlite.Dialector
+}
+
+func (d sqliteDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return sqliteMigrator{d.Dialector.Migrator(db).(sqlite.Migrator)}
+}
+
+type sqliteMigrator struct {
+	sqlite.Migrator
+}
+
+func (m sqliteMigrator) FullDataTypeOf(field *schema.Field) clause.Expr {
+	if strings.HasSuffix(field.DefaultValue, "()") {
+		copied := *field
+		copied.DefaultValue = "(" + field.DefaultValue + ")"
+		field = &copied
+	}
+	return m.Migrator.FullDataTypeOf(field)
+}
+
+// normalizeDates stores date columns as YYYY-MM-DD, like Postgres, so they compare with date strings
+// SQLite keeps whatever text the driver writes, which for time.Time includes the time of day
+func normalizeDates(db *gorm.DB, tables []interface{}) error {
+	for _, table := range tables {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(table); err != nil {
+			return err
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DataType != "date" {
+				continue
+			}
+			for _, event := range []string{"INSERT", "UPDATE OF " + field.DBName} {
+				trigger := fmt.Sprintf("%s_%s_%s", stmt.Table, field.DBName, strings.Fields(event)[0])
+				err := db.Exec(fmt.Sprintf(
+					"CREATE TRIGGER %s AFTER %s ON %s BEGIN UPDATE %s SET %s = date(NEW.%s) WHERE rowid = NEW.rowid; END",
+					trigger, event, stmt.Table, stmt.Table, field.DBName, field.DBName,
+				)).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// testUser is an account in auth.users with a signed access token
+type testUser struct {
+	ID    uuid.UUID
+	Name  string
+	Email string
+	Token string
+}
+
+// user adds an account to auth.users without a profile
+func (h *harness) user(name string) testUser {
+	return h.userWithRole(name, "authenticated")
+}
+
+// admin adds an account whose token carries the admin role
+func (h *harness) admin(name string) testUser {
+	return h.userWithRole(name, "admin")
+}
+
+func (h *harness) userWithRole(name, role string) testUser {
+	h.t.Helper()
+
+	u := testUser{
+		ID:    uuid.New(),
+		Name:  name,
+		Email: strings.ToLower(name) + "@example.com",
+	}
+	if err := h.db.Create(&models.User{ID: u.ID, Name: u.Name, Email: u.Email}).Error; err != nil {
+		h.t.Fatalf("create user: %v", err)
+	}
+	u.Token = signToken(h.t, u.ID, u.Email, role, time.Now().Add(time.Hour))
+	return u
+}
+
+// signUp adds an account and creates its profile through the API, like a new user would
+func (h *harness) signUp(name string) testUser {
+	h.t.Helper()
+
+	u := h.user(name)
+	h.expect(h.do(http.MethodPost, "/api/v1/profile", u, gin.H{"name": name}), http.StatusCreated)
+	return u
+}
+
+// signToken mints an HS256 access token like the ones Supabase issues
+func signToken(t *testing.T, userID uuid.UUID, email, role string, expiresAt time.Time) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   userID.String(),
+		"email": email,
+		"role":  role,
+		"aud":   "authenticated",
+		"iat":   time.Now().Add(-time.Minute).Unix(),
+		"exp":   expiresAt.Unix(),
+	}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+// do sends a JSON request as the user; a zero user sends no Authorization header
+func (h *harness) do(method, path string, u testUser, body interface{}) *httptest.ResponseRecorder {
+	h.t.Helper()
+	return h.send(h.request(method, path, body), u.Token)
+}
+
+// doUnchecked sends a JSON request that breaks the API contract on purpose, to test the handlers' own validation
+// It is served by an engine that only logs OpenAPI violations instead of rejecting them
+func (h *harness) doUnchecked(method, path string, u testUser, body interface{}) *httptest.ResponseRecorder {
+	h.t.Helper()
+	return h.serve(h.unchecked, h.request(method, path, body), u.Token)
+}
+
+func (h *harness) request(method, path string, body interface{}) *http.Request {
+	h.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			h.t.Fatalf("encode body: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req
+}
+
+// upload sends a multipart form with one file field as the user
+func (h *harness) upload(path string, u testUser, field, filename string, content []byte) *httptest.ResponseRecorder {
+	h.t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile(field, filename)
+	if err != nil {
+		h.t.Fatalf("create form file: %v", err)
+	}
+	part.Write(content)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return h.send(req, u.Token)
+}
+
+// send serves the request with a bearer token, unless token is empty
+func (h *harness) send(req *http.Request, token string) *httptest.ResponseRecorder {
+	return h.serve(h.engine, req, token)
+}
+
+func (h *harness) serve(engine *gin.Engine, req *http.Request, token string) *httptest.ResponseRecorder {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	coverage.record(req)
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+// expect fails the test unless the response has the status
+func (h *harness) expect(rec *httptest.ResponseRecorder, status int) {
+	h.t.Helper()
+	if rec.Code != status {
+		h.t.Fatalf("expected status %d, got %d: %s", status, rec.Code, rec.Body.String())
+	}
+}
+
+// expectError fails the test unless the response has the status and error message
+func (h *harness) expectError(rec *httptest.ResponseRecorder, status int, message string) {
+	h.t.Helper()
+	h.expect(rec, status)
+	if got := decode[gin.H](h.t, rec)["error"]; got != message {
+		h.t.Fatalf("expected error %q, got %q", message, got)
+	}
+}
+
+// decode parses the JSON response body
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var value T
+	if err := json.Unmarshal(rec.Body.Bytes(), &value); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	return value
+}
+
+// memoryStorage keeps uploaded profile pictures in memory
+type memoryStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{files: map[string][]byte{}}
+}
+
+func (s *memoryStorage) UploadProfilePicture(userID uuid.UUID, file multipart.File, header *multipart.FileHeader, userToken string) (string, error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	url := fmt.Sprintf("https://storage.example.com/%s/%s", userID, header.Filename)
+	s.files[url] = content
+	return url, nil
+}
+
+func (s *memoryStorage) DeleteProfilePicture(url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.files[url]; !ok {
+		return fmt.Errorf("no such file: %s", url)
+	}
+	delete(s.files, url)
+	return nil
+}
+
+func (s *memoryStorage) has(url string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.files[url]
+	return ok
+}
+
+// coverage tracks which registered routes the tests requested
+var coverage = &routeCoverage{hit: map[string]bool{}}
+
+type routeCoverage struct {
+	mu     sync.Mutex
+	router *gin.Engine // Same routes as the API, recording the matched route
+	routes map[string]bool
+	hit    map[string]bool
+}
+
+// register adds the engine's routes to the routes that need a test
+func (rc *routeCoverage) register(engine *gin.Engine) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.router == nil {
+		rc.router = gin.New()
+		rc.routes = map[string]bool{}
+	}
+	for _, route := range engine.Routes() {
+		key := route.Method + " " + route.Path
+		if rc.routes[key] {
+			continue
+		}
+		rc.routes[key] = true
+		rc.router.Handle(route.Method, route.Path, func(c *gin.Context) {
+			rc.hit[c.Request.Method+" "+c.FullPath()] = true
+		})
+	}
+}
+
+// record marks the route matching the request as tested
+func (rc *routeCoverage) record(req *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	probe := httptest.NewRequest(req.Method, req.URL.String(), nil)
+	rc.router.ServeHTTP(httptest.NewRecorder(), probe)
+}
+
+// missing returns the registered routes no test requested
+func (rc *routeCoverage) missing() []string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	var missing []string
+	for route := range rc.routes {
+		if !rc.hit[route] {
+			missing = append(missing, route)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
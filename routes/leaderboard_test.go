@@ -0,0 +1,137 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestLeaderboard(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+	carol := h.signUp("Carol")
+	dave := h.signUp("Dave")
+
+	h.track(alice, nil, 3*time.Hour)
+	h.track(bob, nil, 2*time.Hour)
+	h.track(carol, nil, time.Hour)
+	h.track(dave, nil, 30*time.Minute)
+
+	// Carol only shows her name to friends, Dave is not ranked at all
+	h.expect(h.do(http.MethodPut, "/api/v1/profile", carol, gin.H{"leaderboard_visibility": models.VisibilityFriends}), http.StatusOK)
+	h.expect(h.do(http.MethodPut, "/api/v1/profile", dave, gin.H{"leaderboard_visibility": models.VisibilityHidden}), http.StatusOK)
+	h.befriend(alice, carol)
+
+	leaderboard := func(u testUser, query string) []models.LeaderboardEntry {
+		t.Helper()
+		rec := h.do(http.MethodGet, "/api/v1/leaderboard"+query, u, nil)
+		h.expect(rec, http.StatusOK)
+		return decode[[]models.LeaderboardEntry](t, rec)
+	}
+
+	seenByBob := leaderboard(bob, "?period=week")
+	if len(seenByBob) != 3 {
+		t.Fatalf("expected three ranked users, got %+v", seenByBob)
+	}
+	if seenByBob[0].UserID != alice.ID || seenByBob[0].TotalHours != 3 || seenByBob[1].UserID != bob.ID || !seenByBob[1].IsCurrentUser {
+		t.Fatalf("unexpected ranking %+v", seenByBob)
+	}
+	if seenByBob[2].UserID != uuid.Nil || seenByBob[2].Name == "Carol" {
+		t.Fatalf("expected Carol to be anonymous to Bob, got %+v", seenByBob[2])
+	}
+
+	if seenByAlice := leaderboard(alice, ""); seenByAlice[2].UserID != carol.ID || seenByAlice[2].Name != "Carol" {
+		t.Fatalf("expected Carol to be visible to her friend, got %+v", seenByAlice[2])
+	}
+
+	if friends := leaderboard(alice, "?scope=friends&period=all"); len(friends) != 2 || friends[0].UserID != alice.ID || friends[1].UserID != carol.ID {
+		t.Fatalf("expected Alice and Carol, got %+v", friends)
+	}
+
+	if page := leaderboard(bob, "?limit=1&offset=1"); len(page) != 1 || page[0].UserID != bob.ID {
+		t.Fatalf("expected Bob on the second place, got %+v", page)
+	}
+
+	h.expectError(h.doUnchecked(http.MethodGet, "/api/v1/leaderboard?period=decade", alice, nil), http.StatusBadRequest, "Invalid period (use week, month, year or all)")
+	h.expectError(h.doUnchecked(http.MethodGet, "/api/v1/leaderboard?scope=galaxy", alice, nil), http.StatusBadRequest, "Invalid scope (use global or friends)")
+}
+
+func TestTeamLeaderboard(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+	carol := h.signUp("Carol")
+
+	acme := h.createWorkspace(alice, "Acme")
+	h.join(alice, acme.ID, bob, models.WorkspaceRoleMember)
+	globex := h.createWorkspace(carol, "Globex")
+	h.createWorkspace(carol, "Private")
+
+	// Teams opt into the leaderboard
+	h.expect(h.do(http.MethodPut, "/api/v1/workspaces/"+acme.ID.String(), alice, gin.H{"name": "Acme", "leaderboard_visible": true}), http.StatusOK)
+	h.expect(h.do(http.MethodPut, "/api/v1/workspaces/"+globex.ID.String(), carol, gin.H{"name": "Globex", "leaderboard_visible": true}), http.StatusOK)
+
+	h.track(alice, nil, time.Hour)
+	h.track(bob, nil, time.Hour)
+	h.track(carol, nil, 90*time.Minute)
+
+	rec := h.do(http.MethodGet, "/api/v1/leaderboard/teams?period=month", bob, nil)
+	h.expect(rec, http.StatusOK)
+	teams := decode[[]models.TeamLeaderboardEntry](t, rec)
+	if len(teams) != 2 {
+		t.Fatalf("expected the two visible teams, got %+v", teams)
+	}
+	if teams[0].TeamID != acme.ID || teams[0].TotalHours != 2 || teams[0].MemberCount != 2 || !teams[0].IsCurrentTeam {
+		t.Fatalf("expected Acme first, got %+v", teams[0])
+	}
+	if teams[1].TeamID != globex.ID || teams[1].IsCurrentTeam {
+		t.Fatalf("expected Globex second, got %+v", teams[1])
+	}
+
+	h.expectError(h.doUnchecked(http.MethodGet, "/api/v1/leaderboard/teams?period=decade", bob, nil), http.StatusBadRequest, "Invalid period (use week, month, year or all)")
+}
+
+func TestTeamReport(t *testing.T) {
+	h := newHarness(t)
+	owner := h.signUp("Owner")
+	alice := h.signUp("Alice")
+
+	workspace := h.createWorkspace(owner, "Acme")
+	h.join(owner, workspace.ID, alice, models.WorkspaceRoleMember)
+	project := h.createProject(owner, gin.H{"name": "Shared", "workspace_id": workspace.ID})
+
+	h.track(alice, &project.ID, 2*time.Hour)
+	h.track(alice, nil, time.Hour)
+
+	report := "/api/v1/teams/" + workspace.ID.String() + "/report"
+	h.expectError(h.do(http.MethodGet, report, alice, nil), http.StatusForbidden, "Insufficient workspace role")
+	h.expectError(h.doUnchecked(http.MethodGet, report+"?interval=hour", owner, nil), http.StatusBadRequest, "Invalid interval (use day, week or month)")
+	h.expectError(h.doUnchecked(http.MethodGet, report+"?period=decade", owner, nil), http.StatusBadRequest, "Invalid period (use week, month, year or all)")
+	h.expectError(h.do(http.MethodGet, "/api/v1/teams/nope/report", owner, nil), http.StatusBadRequest, "Invalid ID")
+
+	rec := h.do(http.MethodGet, report+"?period=week&interval=day", owner, nil)
+	h.expect(rec, http.StatusOK)
+	got := decode[models.TeamReport](t, rec)
+	if got.TeamID != workspace.ID || got.TotalHours != 2 || len(got.Members) != 2 {
+		t.Fatalf("unexpected report %+v", got)
+	}
+
+	var member *models.TeamReportMember
+	for i := range got.Members {
+		if got.Members[i].UserID == alice.ID {
+			member = &got.Members[i]
+		}
+	}
+	// Alice's time on her personal General project is not the team's business
+	if member == nil || member.TotalHours != 2 || len(member.Projects) != 1 || member.Projects[0].ProjectID != project.ID {
+		t.Fatalf("expected only Alice's time on the shared project, got %+v", member)
+	}
+	if last := member.Periods[len(member.Periods)-1]; last.Start != time.Now().UTC().Format("2006-01-02") || last.Hours != 2 {
+		t.Fatalf("expected today's hours in the last period, got %+v", member.Periods)
+	}
+}
@@ -0,0 +1,110 @@
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time-tracker/config"
+	"time-tracker/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRoutesMatchSpec(t *testing.T) {
+	// The development token route is only registered in insecure development mode
+	h := newHarness(t, func(cfg *config.Config) {
+		cfg.Auth.Mode = config.AuthModeInsecureDev
+		cfg.Auth.JWT.Secret = ""
+	})
+
+	registered := map[string]bool{}
+	for _, route := range h.engine.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	documented := map[string]bool{}
+	for _, operation := range spec.Operations() {
+		documented[operation] = true
+	}
+
+	var undocumented, unregistered []string
+	for route := range registered {
+		if !documented[route] {
+			undocumented = append(undocumented, route)
+		}
+	}
+	for operation := range documented {
+		if !registered[operation] {
+			unregistered = append(unregistered, operation)
+		}
+	}
+	sort.Strings(undocumented)
+	sort.Strings(unregistered)
+
+	for _, route := range undocumented {
+		t.Errorf("%s is registered but missing from openapi.yaml", route)
+	}
+	for _, operation := range unregistered {
+		t.Errorf("%s is in openapi.yaml but not registered", operation)
+	}
+}
+
+func TestOpenAPIValidation(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+
+	// Strict mode rejects requests the spec does not allow before they reach the handler
+	rec := h.do(http.MethodGet, "/api/v1/leaderboard?period=decade", alice, nil)
+	h.expect(rec, http.StatusBadRequest)
+	if got := decode[gin.H](t, rec)["error"]; got == "Invalid period (use week, month, year or all)" {
+		t.Fatalf("expected the request to be rejected by the validator, got %q", got)
+	}
+
+	// Authentication answers before validation
+	h.expect(h.do(http.MethodGet, "/api/v1/leaderboard?period=decade", testUser{}, nil), http.StatusUnauthorized)
+
+	// Log mode leaves them to the handler
+	h.expectError(h.doUnchecked(http.MethodGet, "/api/v1/leaderboard?period=decade", alice, nil), http.StatusBadRequest, "Invalid period (use week, month, year or all)")
+
+	serve := func(mode, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		engine := gin.New()
+		engine.Use(middleware.OpenAPIValidation(spec, config.OpenAPI{Mode: mode, ValidateResponses: true}))
+		engine.GET("/health", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"status": 1}) // The spec says status is a string
+		})
+		engine.GET("/undocumented", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		})
+
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	// Responses that drift from the spec are replaced in strict mode and only logged otherwise
+	if rec := serve(config.OpenAPIModeStrict, "/health"); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected the invalid response to be replaced, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(config.OpenAPIModeLog, "/health"); rec.Code != http.StatusOK || rec.Body.String() != `{"status":1}` {
+		t.Fatalf("expected the response to pass through, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(config.OpenAPIModeStrict, "/undocumented"); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected undocumented routes to fail, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(config.OpenAPIModeOff, "/health"); rec.Code != http.StatusOK {
+		t.Fatalf("expected no validation, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Responses are only checked when enabled
+	engine := gin.New()
+	engine.Use(middleware.OpenAPIValidation(spec, config.OpenAPI{Mode: config.OpenAPIModeStrict}))
+	engine.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": 1})
+	})
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"status":1}` {
+		t.Fatalf("expected the response to go unchecked, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
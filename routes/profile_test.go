@@ -0,0 +1,230 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"time-tracker/jobs"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestProfile(t *testing.T) {
+	h := newHarness(t)
+	alice := h.user("Alice")
+
+	h.expectError(h.do(http.MethodPut, "/api/v1/profile", alice, gin.H{"bio": "Hi"}), http.StatusNotFound, "Profile not found")
+	h.expect(h.do(http.MethodPost, "/api/v1/profile", alice, gin.H{}), http.StatusBadRequest)
+
+	rec := h.do(http.MethodPost, "/api/v1/profile", alice, gin.H{"name": "Alice"})
+	h.expect(rec, http.StatusCreated)
+	if got := decode[models.Profile](t, rec); got.ID != alice.ID || got.Name != "Alice" || got.Timezone != "UTC" {
+		t.Fatalf("unexpected profile %+v", got)
+	}
+	h.expectError(h.do(http.MethodPost, "/api/v1/profile", alice, gin.H{"name": "Alice"}), http.StatusConflict, "Profile already exists")
+
+	h.track(alice, nil, 2*time.Hour)
+
+	rec = h.do(http.MethodGet, "/api/v1/profile", alice, nil)
+	h.expect(rec, http.StatusOK)
+	profile := decode[models.UserResponse](t, rec)
+	if profile.Email != alice.Email || profile.TotalSessions != 1 || profile.TotalHours != 2 || profile.CurrentStreak != 1 {
+		t.Fatalf("unexpected profile %+v", profile)
+	}
+	if profile.Rank != 1 || profile.Level == "" || len(profile.Achievements) != 1 {
+		t.Fatalf("expected a rank, level and achievement, got %+v", profile)
+	}
+
+	t.Run("update", func(t *testing.T) {
+		h.expectError(h.do(http.MethodPut, "/api/v1/profile", alice, gin.H{"timezone": "Mars/Olympus"}), http.StatusBadRequest, "Invalid timezone")
+		h.expectError(h.do(http.MethodPut, "/api/v1/profile", alice, gin.H{"locale": "!!"}), http.StatusBadRequest, "Invalid locale")
+		h.expect(h.do(http.MethodPut, "/api/v1/profile", alice, gin.H{"week_start_day": 7}), http.StatusBadRequest)
+
+		rec := h.do(http.MethodPut, "/api/v1/profile", alice, gin.H{
+			"name":                   "Alice Liddell",
+			"bio":                    "Tracking time",
+			"timezone":               "Europe/Berlin",
+			"week_start_day":         0,
+			"locale":                 "de-de",
+			"time_format":            "12h",
+			"leaderboard_visibility": "hidden",
+		})
+		h.expect(rec, http.StatusOK)
+		updated := decode[models.Profile](t, rec)
+		if updated.Name != "Alice Liddell" || updated.Bio != "Tracking time" || updated.Timezone != "Europe/Berlin" || updated.WeekStartDay != 0 ||
+			updated.Locale != "de-DE" || updated.TimeFormat != "12h" || updated.Visibility != "hidden" {
+			t.Fatalf("unexpected profile %+v", updated)
+		}
+
+		// The new name is read back, and hidden users are not ranked
+		rec = h.do(http.MethodGet, "/api/v1/profile", alice, nil)
+		h.expect(rec, http.StatusOK)
+		got := decode[models.UserResponse](t, rec)
+		if got.Name != "Alice Liddell" {
+			t.Fatalf("expected the new name, got %q", got.Name)
+		}
+		if got.Rank != 0 {
+			t.Fatalf("expected no rank, got %d", got.Rank)
+		}
+	})
+
+	t.Run("achievements", func(t *testing.T) {
+		rec := h.do(http.MethodGet, "/api/v1/achievements", alice, nil)
+		h.expect(rec, http.StatusOK)
+		list := decode[[]models.AchievementResponse](t, rec)
+		if len(list) != len(models.DefaultAchievements) {
+			t.Fatalf("expected every achievement, got %d", len(list))
+		}
+		for _, achievement := range list {
+			if unlocked := achievement.Code == "first_entry"; achievement.Unlocked != unlocked {
+				t.Fatalf("unexpected unlock status for %s", achievement.Code)
+			}
+		}
+	})
+}
+
+func TestProfileWithoutUser(t *testing.T) {
+	h := newHarness(t)
+	ghost := testUser{Token: signToken(t, uuid.New(), "ghost@example.com", "authenticated", time.Now().Add(time.Hour))}
+
+	h.expectError(h.do(http.MethodGet, "/api/v1/profile", ghost, nil), http.StatusNotFound, "User not found")
+}
+
+func TestProfilePicture(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+
+	h.expectError(h.doUnchecked(http.MethodPost, "/api/v1/profile/picture", alice, nil), http.StatusBadRequest, "No file uploaded")
+	h.expectError(h.do(http.MethodDelete, "/api/v1/profile/picture", alice, nil), http.StatusBadRequest, "No profile picture to delete")
+
+	rec := h.upload("/api/v1/profile/picture", alice, "file", "me.png", []byte("first"))
+	h.expect(rec, http.StatusOK)
+	first, _ := decode[gin.H](t, rec)["profile_picture_url"].(string)
+	if !h.storage.has(first) {
+		t.Fatalf("expected %q in storage", first)
+	}
+
+	// A new picture replaces the old one
+	rec = h.upload("/api/v1/profile/picture", alice, "file", "me-too.png", []byte("second"))
+	h.expect(rec, http.StatusOK)
+	second, _ := decode[gin.H](t, rec)["profile_picture_url"].(string)
+	if h.storage.has(first) || !h.storage.has(second) {
+		t.Fatal("expected the old picture to be replaced")
+	}
+
+	rec = h.do(http.MethodGet, "/api/v1/profile", alice, nil)
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.UserResponse](t, rec).ProfilePictureURL; got == nil || *got != second {
+		t.Fatalf("expected picture %q on the profile, got %v", second, got)
+	}
+
+	h.expect(h.do(http.MethodDelete, "/api/v1/profile/picture", alice, nil), http.StatusOK)
+	if h.storage.has(second) {
+		t.Fatal("expected the picture to be deleted from storage")
+	}
+	h.expectError(h.do(http.MethodDelete, "/api/v1/profile/picture", alice, nil), http.StatusBadRequest, "No profile picture to delete")
+}
+
+func TestAccountDeletion(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+
+	h.expectError(h.do(http.MethodPost, "/api/v1/profile/cancel-deletion", alice, nil), http.StatusBadRequest, "No account deletion scheduled")
+	h.expect(h.do(http.MethodDelete, "/api/v1/profile", alice, gin.H{}), http.StatusBadRequest)
+	h.expectError(h.do(http.MethodDelete, "/api/v1/profile", alice, gin.H{"confirm": "yes"}), http.StatusBadRequest, "Account deletion must be confirmed with \"DELETE\"")
+
+	rec := h.do(http.MethodDelete, "/api/v1/profile", alice, gin.H{"confirm": "DELETE"})
+	h.expect(rec, http.StatusAccepted)
+	scheduled, err := time.Parse(time.RFC3339, decode[gin.H](t, rec)["deletion_scheduled_at"].(string))
+	if err != nil || !scheduled.After(time.Now()) {
+		t.Fatalf("expected the deletion to be scheduled in the future, got %v (%v)", scheduled, err)
+	}
+	h.expectError(h.do(http.MethodDelete, "/api/v1/profile", alice, gin.H{"confirm": "DELETE"}), http.StatusConflict, "Account deletion already scheduled")
+
+	// Once cancelled, the deletion can be scheduled again
+	h.expect(h.do(http.MethodPost, "/api/v1/profile/cancel-deletion", alice, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodPost, "/api/v1/profile/cancel-deletion", alice, nil), http.StatusBadRequest, "No account deletion scheduled")
+	h.expect(h.do(http.MethodDelete, "/api/v1/profile", alice, gin.H{"confirm": "DELETE"}), http.StatusAccepted)
+}
+
+func TestAccountPurgeWorkspaces(t *testing.T) {
+	h := newHarness(t)
+	owner := h.signUp("Owner")
+	alice := h.signUp("Alice")
+	bob := h.user("Bob")
+
+	// Acme passes to Alice, while Solo has nobody left to manage it
+	acme := h.createWorkspace(owner, "Acme")
+	h.join(owner, acme.ID, alice, models.WorkspaceRoleMember)
+	solo := h.createWorkspace(owner, "Solo")
+	project := h.createProject(owner, gin.H{"name": "Website", "workspace_id": solo.ID})
+	h.track(owner, &project.ID, time.Hour)
+	h.invite(owner, solo.ID, bob, models.WorkspaceRoleMember)
+
+	// Carol tracked time on Solo before leaving it
+	carol := h.signUp("Carol")
+	h.join(owner, solo.ID, carol, models.WorkspaceRoleMember)
+	carolsEntry := h.track(carol, &project.ID, time.Hour)
+	h.expect(h.do(http.MethodDelete, "/api/v1/workspaces/"+solo.ID.String()+"/members/"+carol.ID.String(), owner, nil), http.StatusOK)
+
+	// Dave's time on Archive is in a locked period, so Archive must stay as it is
+	admin := h.admin("Admin")
+	dave := h.signUp("Dave")
+	archive := h.createWorkspace(owner, "Archive")
+	h.join(owner, archive.ID, dave, models.WorkspaceRoleMember)
+	archived := h.createProject(owner, gin.H{"name": "Old site", "workspace_id": archive.ID})
+	davesEntry := h.track(dave, &archived.ID, time.Hour)
+	h.expect(h.do(http.MethodDelete, "/api/v1/workspaces/"+archive.ID.String()+"/members/"+dave.ID.String(), owner, nil), http.StatusOK)
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/period-locks", admin, gin.H{"locked_before": tomorrow, "user_id": dave.ID}), http.StatusCreated)
+
+	var profile models.Profile
+	if err := h.db.First(&profile, "id = ?", owner.ID).Error; err != nil {
+		t.Fatalf("find profile: %v", err)
+	}
+	if err := jobs.NewAccountDeletion(h.db, h.storage).PurgeAccount(profile); err != nil {
+		t.Fatalf("purge account: %v", err)
+	}
+
+	var member models.WorkspaceMember
+	if err := h.db.First(&member, "workspace_id = ? AND user_id = ?", acme.ID, alice.ID).Error; err != nil || member.Role != models.WorkspaceRoleOwner {
+		t.Fatalf("expected Alice to own Acme, got %+v (%v)", member, err)
+	}
+
+	// Solo is gone with its project, member and invitation
+	remaining := map[string]*gorm.DB{
+		"workspace":   h.db.Model(&models.Workspace{}).Where("id = ?", solo.ID),
+		"projects":    h.db.Unscoped().Model(&models.Project{}).Where("workspace_id = ?", solo.ID),
+		"members":     h.db.Model(&models.WorkspaceMember{}).Where("workspace_id = ?", solo.ID),
+		"invitations": h.db.Model(&models.WorkspaceInvitation{}).Where("workspace_id = ?", solo.ID),
+	}
+	for name, query := range remaining {
+		var count int64
+		if err := query.Count(&count).Error; err != nil || count != 0 {
+			t.Fatalf("expected no %s left in Solo, got %d (%v)", name, count, err)
+		}
+	}
+
+	// Carol keeps her time without the project, and both changes are audited
+	var entry models.TimeEntry
+	if err := h.db.First(&entry, "id = ?", carolsEntry.ID).Error; err != nil || entry.ProjectID != nil {
+		t.Fatalf("expected Carol's entry without a project, got %+v (%v)", entry, err)
+	}
+	for _, id := range []uuid.UUID{carolsEntry.ID, project.ID} {
+		var count int64
+		if err := h.db.Model(&models.AuditLog{}).Where("entity_id = ? AND actor_id IS NULL", id).Count(&count).Error; err != nil || count != 1 {
+			t.Fatalf("expected the change to %s to be audited, got %d (%v)", id, count, err)
+		}
+	}
+
+	if err := h.db.First(&models.Workspace{}, "id = ?", archive.ID).Error; err != nil {
+		t.Fatalf("expected Archive to be kept: %v", err)
+	}
+	var locked models.TimeEntry
+	if err := h.db.First(&locked, "id = ?", davesEntry.ID).Error; err != nil || locked.ProjectID == nil || *locked.ProjectID != archived.ID {
+		t.Fatalf("expected Dave's locked entry untouched, got %+v (%v)", locked, err)
+	}
+}
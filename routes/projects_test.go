@@ -0,0 +1,160 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createProject creates a project as the user
+func (h *harness) createProject(u testUser, body gin.H) models.ProjectResponse {
+	h.t.Helper()
+
+	rec := h.do(http.MethodPost, "/api/v1/projects", u, body)
+	h.expect(rec, http.StatusCreated)
+	return decode[models.ProjectResponse](h.t, rec)
+}
+
+func TestProjectLifecycle(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+
+	h.expect(h.do(http.MethodPost, "/api/v1/projects", alice, gin.H{}), http.StatusBadRequest)
+	h.expectError(h.do(http.MethodPost, "/api/v1/projects", alice, gin.H{"name": "General"}), http.StatusBadRequest, "Project name 'General' is reserved")
+
+	project := h.createProject(alice, gin.H{"name": "Website", "description": "Relaunch"})
+	if project.Color != models.DefaultProjectColor || project.WorkspaceID != nil {
+		t.Fatalf("expected a personal project with the default color, got %+v", project)
+	}
+	path := "/api/v1/projects/" + project.ID.String()
+
+	rec := h.do(http.MethodGet, path, alice, nil)
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.ProjectResponse](t, rec); got.Name != "Website" || got.Description != "Relaunch" {
+		t.Fatalf("unexpected project %+v", got)
+	}
+
+	rec = h.do(http.MethodGet, "/api/v1/projects?limit=1", alice, nil)
+	h.expect(rec, http.StatusOK)
+	list := decode[models.PaginatedProjectResponse](t, rec)
+	if list.Total != 2 || list.TotalPages != 2 || len(list.Data) != 1 {
+		t.Fatalf("expected the General and Website projects one per page, got %+v", list)
+	}
+
+	h.expectError(h.do(http.MethodPut, path, alice, gin.H{"name": "General"}), http.StatusBadRequest, "Project name 'General' is reserved")
+
+	rec = h.do(http.MethodPut, path, alice, gin.H{"name": "Web", "color": "#FF0000"})
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.ProjectResponse](t, rec); got.Name != "Web" || got.Color != "#FF0000" || got.Description != "Relaunch" {
+		t.Fatalf("expected only the name and color to change, got %+v", got)
+	}
+
+	// Entries on a deleted project are kept
+	entry := h.startEntry(alice, &project.ID)
+	h.expect(h.do(http.MethodDelete, path, alice, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodGet, path, alice, nil), http.StatusNotFound, "Project not found")
+	h.expect(h.do(http.MethodGet, "/api/v1/time-entries/"+entry.ID.String(), alice, nil), http.StatusOK)
+}
+
+func TestProjectDeleteLockedEntries(t *testing.T) {
+	h := newHarness(t)
+	admin := h.admin("Admin")
+	manager := h.signUp("Manager")
+	alice := h.signUp("Alice")
+
+	workspace := h.createWorkspace(manager, "Acme")
+	h.join(manager, workspace.ID, alice, models.WorkspaceRoleMember)
+	project := h.createProject(alice, gin.H{"name": "Website"})
+	path := "/api/v1/projects/" + project.ID.String()
+	// Tracked last week, which has ended and can be submitted
+	entry := h.track(alice, &project.ID, time.Hour)
+	h.moveEntry(entry.ID, -7*24*time.Hour)
+
+	const message = "Project has time entries in a locked period or approved timesheet"
+
+	// Lock Alice's time up to and including today
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	rec := h.do(http.MethodPost, "/api/v1/admin/period-locks", admin, gin.H{"locked_before": tomorrow, "user_id": alice.ID})
+	h.expect(rec, http.StatusCreated)
+	lock := decode[models.PeriodLockResponse](t, rec)
+	h.expectError(h.do(http.MethodDelete, path, alice, nil), http.StatusConflict, message)
+	h.expect(h.do(http.MethodDelete, "/api/v1/admin/period-locks/"+lock.ID.String(), admin, nil), http.StatusOK)
+
+	// Approve the week instead
+	rec = h.do(http.MethodPost, "/api/v1/timesheets/submit", alice, nil)
+	h.expect(rec, http.StatusCreated)
+	timesheet := decode[models.TimesheetResponse](t, rec)
+	h.expect(h.do(http.MethodPost, "/api/v1/timesheets/"+timesheet.ID.String()+"/approve", manager, gin.H{}), http.StatusOK)
+	h.expectError(h.do(http.MethodDelete, path, alice, nil), http.StatusConflict, message)
+
+	rec = h.do(http.MethodGet, "/api/v1/time-entries/"+entry.ID.String(), alice, nil)
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.TimeEntryResponse](t, rec); got.Project == nil || got.Project.ID != project.ID {
+		t.Fatalf("expected the entry to stay on the project, got %+v", got)
+	}
+	h.expect(h.do(http.MethodGet, path, alice, nil), http.StatusOK)
+}
+
+func TestProjectOwnership(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+
+	project := h.createProject(alice, gin.H{"name": "Website"})
+	path := "/api/v1/projects/" + project.ID.String()
+
+	h.expectError(h.do(http.MethodGet, path, bob, nil), http.StatusNotFound, "Project not found")
+	h.expectError(h.do(http.MethodPut, path, bob, gin.H{"name": "Mine"}), http.StatusNotFound, "Project not found")
+	h.expectError(h.do(http.MethodDelete, path, bob, nil), http.StatusNotFound, "Project not found")
+	h.expectError(h.do(http.MethodGet, "/api/v1/projects/not-a-uuid", bob, nil), http.StatusBadRequest, "Invalid ID")
+
+	rec := h.do(http.MethodGet, "/api/v1/projects", bob, nil)
+	h.expect(rec, http.StatusOK)
+	for _, p := range decode[models.PaginatedProjectResponse](t, rec).Data {
+		if p.ID == project.ID {
+			t.Fatal("expected Alice's project to be hidden from Bob")
+		}
+	}
+}
+
+func TestSharedProjects(t *testing.T) {
+	h := newHarness(t)
+	owner := h.signUp("Owner")
+	member := h.signUp("Member")
+	viewer := h.signUp("Viewer")
+	outsider := h.signUp("Outsider")
+
+	workspace := h.createWorkspace(owner, "Acme")
+	h.join(owner, workspace.ID, member, models.WorkspaceRoleMember)
+	h.join(owner, workspace.ID, viewer, models.WorkspaceRoleViewer)
+
+	h.expectError(h.do(http.MethodPost, "/api/v1/projects", member, gin.H{"name": "Shared", "workspace_id": workspace.ID}), http.StatusForbidden, "Insufficient workspace role")
+	h.expectError(h.do(http.MethodPost, "/api/v1/projects", outsider, gin.H{"name": "Shared", "workspace_id": workspace.ID}), http.StatusNotFound, "Workspace not found")
+
+	project := h.createProject(owner, gin.H{"name": "Shared", "workspace_id": workspace.ID})
+	if project.WorkspaceID == nil || *project.WorkspaceID != workspace.ID {
+		t.Fatalf("expected a project in workspace %s, got %+v", workspace.ID, project)
+	}
+	path := "/api/v1/projects/" + project.ID.String()
+
+	// Every member sees the project, only admins manage it
+	h.expect(h.do(http.MethodGet, path, viewer, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodPut, path, member, gin.H{"name": "Renamed"}), http.StatusForbidden, "Insufficient workspace role")
+	h.expectError(h.do(http.MethodDelete, path, member, nil), http.StatusForbidden, "Insufficient workspace role")
+	h.expectError(h.do(http.MethodGet, path, outsider, nil), http.StatusNotFound, "Project not found")
+
+	rec := h.do(http.MethodGet, "/api/v1/projects?workspace_id="+workspace.ID.String(), member, nil)
+	h.expect(rec, http.StatusOK)
+	if list := decode[models.PaginatedProjectResponse](t, rec); list.Total != 1 || list.Data[0].ID != project.ID {
+		t.Fatalf("expected only the shared project, got %+v", list)
+	}
+	h.expectError(h.do(http.MethodGet, "/api/v1/projects?workspace_id=nope", member, nil), http.StatusBadRequest, "Invalid workspace ID")
+
+	// Members track time on shared projects, viewers only look
+	h.startEntry(member, &project.ID)
+	h.expectError(h.do(http.MethodPost, "/api/v1/time-entries", viewer, gin.H{"project_id": project.ID}), http.StatusForbidden, "Insufficient workspace role")
+	h.expectError(h.do(http.MethodPost, "/api/v1/time-entries", outsider, gin.H{"project_id": project.ID}), http.StatusBadRequest, "Project not found")
+}
@@ -1,75 +1,226 @@
 package routes
 
 import (
+	"log"
+	"time-tracker/apispec"
+	"time-tracker/config"
 	"time-tracker/handlers"
 	"time-tracker/middleware"
+	"time-tracker/models"
+	"time-tracker/ratelimit"
+	"time-tracker/repository"
+	"time-tracker/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-func SetupRoutes() *gin.Engine {
+// Dependencies are the external resources the handlers are built on
+type Dependencies struct {
+	DB       *gorm.DB
+	Storage  services.PictureStorage // Profile picture storage
+	Verifier *middleware.Verifier    // Checks access token signatures and claims
+	Spec     *apispec.Spec           // API specification to validate against, nil to skip validation
+}
+
+// SetupRoutes builds the API engine from validated settings and its dependencies
+func SetupRoutes(cfg *config.Config, deps Dependencies) *gin.Engine {
+	// Authentication and admin checks for every protected route
+	auth := middleware.NewAuthenticator(cfg.Auth, deps.Verifier, deps.DB)
+
+	// Repositories and services behind the time entry, project and profile handlers
+	timeEntryRepo := repository.NewTimeEntries(deps.DB)
+	projectRepo := repository.NewProjects(deps.DB)
+	profileRepo := repository.NewProfiles(deps.DB)
+	achievementRepo := repository.NewAchievements(deps.DB)
+
+	timeEntryHandler := handlers.NewTimeEntryHandler(services.NewTimeEntryService(timeEntryRepo, projectRepo, achievementRepo))
+	projectService := services.NewProjectService(projectRepo)
+	projectHandler := handlers.NewProjectHandler(projectService)
+	profileHandler := handlers.NewProfileHandler(services.NewProfileService(profileRepo, projectRepo, deps.Storage, cfg.AccountDeletion.GracePeriod()))
+
+	// Handlers working on the database directly
+	workspaceHandler := handlers.NewWorkspaceHandler(deps.DB, projectService)
+	goalHandler := handlers.NewGoalHandler(deps.DB, projectService)
+	timesheetHandler := handlers.NewTimesheetHandler(deps.DB)
+	tokenHandler := handlers.NewTokenHandler(deps.DB)
+	friendHandler := handlers.NewFriendHandler(deps.DB)
+	achievementHandler := handlers.NewAchievementHandler(deps.DB)
+	leaderboardHandler := handlers.NewLeaderboardHandler(deps.DB)
+	teamHandler := handlers.NewTeamHandler(deps.DB, projectService)
+	auditHandler := handlers.NewAuditHandler(deps.DB)
+	levelTierHandler := handlers.NewLevelTierHandler(deps.DB)
+	periodLockHandler := handlers.NewPeriodLockHandler(deps.DB)
+	adminHandler := handlers.NewAdminHandler(deps.DB)
+
 	r := gin.Default()
 
 	// Disable automatic redirect of trailing slash
 	r.RedirectTrailingSlash = false
 
-	// CORS middleware
-	r.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	// Only trust forwarding headers from known proxies, so clients cannot pick their own IP
+	// and escape the per-IP rate limits
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+	// Tag every request with an ID for logs and the audit log
+	r.Use(middleware.RequestID())
 
-		c.Next()
-	})
+	// CORS middleware
+	r.Use(middleware.CORS(cfg.CORS))
+
+	// Check requests and responses against openapi.yaml, after authentication and rate limits
+	validate := middleware.OpenAPIValidation(deps.Spec, cfg.OpenAPI)
 
 	// Health check
-	r.GET("/health", func(c *gin.Context) {
+	r.GET("/health", validate, func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Token bucket rate limits per route group, keyed by user after authentication and by IP before
+	limiter := middleware.NewRateLimiter(ratelimit.NewMemoryStore(), cfg.RateLimit)
+
 	// API routes
 	api := r.Group("/api/v1")
+	api.Use(limiter.Limit("ip"))
 
 	// Time entry routes (requires authentication)
 	timeEntries := api.Group("/time-entries")
-	timeEntries.Use(middleware.SupabaseAuth()) // Apply authentication middleware
+	timeEntries.Use(auth.SupabaseAuth(), limiter.Limit("time_entries"), middleware.RequireResourceScope("time_entries"), validate) // Apply authentication, rate limit, token scope and validation middleware
 	{
-		timeEntries.POST("", handlers.CreateTimeEntry)
-		timeEntries.GET("", handlers.GetTimeEntries)
-		timeEntries.GET("/:id", handlers.GetTimeEntry)
-		timeEntries.PUT("/:id", handlers.UpdateTimeEntry)
-		timeEntries.POST("/:id/stop", handlers.StopTimeEntry)
-		timeEntries.DELETE("/:id", handlers.DeleteTimeEntry)
+		timeEntries.POST("", timeEntryHandler.Create)
+		timeEntries.GET("", timeEntryHandler.List)
+		timeEntries.GET("/:id", timeEntryHandler.Get)
+		timeEntries.PUT("/:id", timeEntryHandler.Update)
+		timeEntries.POST("/:id/stop", timeEntryHandler.Stop)
+		timeEntries.DELETE("/:id", timeEntryHandler.Delete)
 	}
 
 	// Project routes (requires authentication)
 	projects := api.Group("/projects")
-	projects.Use(middleware.SupabaseAuth()) // Apply authentication middleware
+	projects.Use(auth.SupabaseAuth(), limiter.Limit("projects"), middleware.RequireResourceScope("projects"), validate) // Apply authentication, rate limit, token scope and validation middleware
 	{
-		projects.POST("", handlers.CreateProject)
-		projects.GET("", handlers.GetProjects)
-		projects.GET("/:id", handlers.GetProject)
-		projects.PUT("/:id", handlers.UpdateProject)
-		projects.DELETE("/:id", handlers.DeleteProject)
+		projects.POST("", projectHandler.Create)
+		projects.GET("", projectHandler.List)
+		projects.GET("/:id", projectHandler.Get)
+		projects.PUT("/:id", projectHandler.Update)
+		projects.DELETE("/:id", projectHandler.Delete)
+	}
+
+	// Workspace routes (requires authentication)
+	workspaces := api.Group("/workspaces")
+	workspaces.Use(auth.SupabaseAuth(), limiter.Limit("workspaces"), middleware.RequireResourceScope("workspaces"), validate) // Apply authentication, rate limit, token scope and validation middleware
+	{
+		workspaces.POST("", workspaceHandler.Create)
+		workspaces.GET("", workspaceHandler.List)
+		workspaces.POST("/invitations/accept", workspaceHandler.AcceptInvitation)
+		workspaces.GET("/:id", workspaceHandler.Get)
+		workspaces.PUT("/:id", workspaceHandler.Update)
+		workspaces.DELETE("/:id", workspaceHandler.Delete)
+		workspaces.GET("/:id/members", workspaceHandler.ListMembers)
+		workspaces.PUT("/:id/members/:user_id", workspaceHandler.UpdateMember)
+		workspaces.DELETE("/:id/members/:user_id", workspaceHandler.RemoveMember)
+		workspaces.POST("/:id/invitations", workspaceHandler.CreateInvitation)
+		workspaces.GET("/:id/invitations", workspaceHandler.ListInvitations)
+		workspaces.DELETE("/:id/invitations/:invitation_id", workspaceHandler.DeleteInvitation)
 	}
 
 	// Profile routes (requires authentication)
 	profile := api.Group("/profile")
-	profile.Use(middleware.SupabaseAuth()) // Apply authentication middleware
+	profile.Use(auth.SupabaseAuth(), limiter.Limit("profile"), middleware.RequireResourceScope("profile"), validate) // Apply authentication, rate limit, token scope and validation middleware
+	{
+		profile.POST("", profileHandler.Create)
+		profile.GET("", profileHandler.Get)
+		profile.PUT("", profileHandler.Update)
+		profile.DELETE("", profileHandler.DeleteAccount)
+		profile.POST("/cancel-deletion", profileHandler.CancelAccountDeletion)
+		profile.POST("/picture", profileHandler.UploadPicture)
+		profile.DELETE("/picture", profileHandler.DeletePicture)
+	}
+
+	// Goal routes (requires authentication)
+	goals := api.Group("/goals")
+	goals.Use(auth.SupabaseAuth(), limiter.Limit("goals"), middleware.RequireResourceScope("goals"), validate) // Apply authentication, rate limit, token scope and validation middleware
 	{
-		profile.POST("", handlers.CreateProfile)
-		profile.GET("", handlers.GetProfile)
-		profile.POST("/picture", handlers.UploadProfilePicture)
-		profile.DELETE("/picture", handlers.DeleteProfilePicture)
+		goals.POST("", goalHandler.Create)
+		goals.GET("", goalHandler.List)
+		goals.GET("/progress", goalHandler.Progress)
+		goals.GET("/history", goalHandler.History)
+		goals.PUT("/:id", goalHandler.Update)
+		goals.DELETE("/:id", goalHandler.Delete)
 	}
 
-	// Leaderboard route (requires authentication)
-	api.GET("/leaderboard", middleware.SupabaseAuth(), handlers.GetLeaderboard)
+	// Timesheet routes (requires authentication)
+	timesheets := api.Group("/timesheets")
+	timesheets.Use(auth.SupabaseAuth(), limiter.Limit("timesheets"), middleware.RequireResourceScope("timesheets"), validate) // Apply authentication, rate limit, token scope and validation middleware
+	{
+		timesheets.GET("", timesheetHandler.List)
+		timesheets.POST("/submit", timesheetHandler.Submit)
+		timesheets.GET("/pending", timesheetHandler.ListPending)
+		timesheets.GET("/:id", timesheetHandler.Get)
+		timesheets.POST("/:id/approve", timesheetHandler.Approve)
+		timesheets.POST("/:id/reject", timesheetHandler.Reject)
+	}
+
+	// Personal access token routes (requires authentication)
+	tokens := api.Group("/tokens")
+	tokens.Use(auth.SupabaseAuth(), limiter.Limit("tokens"), validate) // Apply authentication, rate limit and validation middleware
+	{
+		tokens.POST("", tokenHandler.Create)
+		tokens.GET("", tokenHandler.List)
+		tokens.DELETE("/:id", tokenHandler.Delete)
+	}
+
+	// Friend routes (requires authentication)
+	friends := api.Group("/friends")
+	friends.Use(auth.SupabaseAuth(), limiter.Limit("friends"), middleware.RequireResourceScope("friends"), validate) // Apply authentication, rate limit, token scope and validation middleware
+	{
+		friends.GET("", friendHandler.List)
+		friends.GET("/requests", friendHandler.ListRequests)
+		friends.POST("/requests", friendHandler.SendRequest)
+		friends.POST("/requests/:id/accept", friendHandler.AcceptRequest)
+		friends.DELETE("/requests/:id", friendHandler.DeleteRequest)
+		friends.GET("/blocked", friendHandler.ListBlocked)
+		friends.DELETE("/:user_id", friendHandler.Remove)
+		friends.POST("/:user_id/block", friendHandler.Block)
+		friends.DELETE("/:user_id/block", friendHandler.Unblock)
+	}
+
+	// Achievements route (requires authentication)
+	api.GET("/achievements", auth.SupabaseAuth(), limiter.Limit("achievements"), middleware.RequireScope(models.ScopeProfileRead), validate, achievementHandler.List)
+
+	// Leaderboard routes (requires authentication)
+	api.GET("/leaderboard", auth.SupabaseAuth(), limiter.Limit("leaderboard"), middleware.RequireScope(models.ScopeLeaderboardRead), validate, leaderboardHandler.Get)
+	api.GET("/leaderboard/teams", auth.SupabaseAuth(), limiter.Limit("leaderboard"), middleware.RequireScope(models.ScopeLeaderboardRead), validate, teamHandler.Leaderboard)
+
+	// Team report route (requires authentication; teams are workspaces)
+	api.GET("/teams/:id/report", auth.SupabaseAuth(), limiter.Limit("reports"), middleware.RequireScope(models.ScopeWorkspacesRead), validate, teamHandler.Report)
+
+	// Audit log route (requires authentication and an admin role)
+	api.GET("/audit", auth.SupabaseAuth(), limiter.Limit("admin"), auth.AdminOnly(), validate, auditHandler.List)
+
+	// Development token route, only in insecure development mode
+	if auth.InsecureDev() {
+		api.POST("/dev/token", limiter.Limit("dev_token"), validate, handlers.NewDevTokenHandler(auth).Issue)
+	}
+
+	// Admin routes (requires authentication and an admin role)
+	admin := api.Group("/admin")
+	admin.Use(auth.SupabaseAuth(), limiter.Limit("admin"), auth.AdminOnly(), validate)
+	{
+		admin.GET("/level-tiers", levelTierHandler.List)
+		admin.POST("/level-tiers", levelTierHandler.Create)
+		admin.PUT("/level-tiers/:id", levelTierHandler.Update)
+		admin.DELETE("/level-tiers/:id", levelTierHandler.Delete)
+		admin.GET("/period-locks", periodLockHandler.List)
+		admin.POST("/period-locks", periodLockHandler.Create)
+		admin.DELETE("/period-locks/:id", periodLockHandler.Delete)
+		admin.GET("/users", adminHandler.ListUsers)
+		admin.GET("/users/:id/time-entries", adminHandler.ListUserTimeEntries)
+		admin.POST("/users/:id/stats/recalculate", adminHandler.RecalculateUserStats)
+		admin.POST("/stats/recalculate", adminHandler.RecalculateStats)
+	}
 
 	return r
 }
@@ -0,0 +1,222 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// startEntry starts a time entry as the user, on the project or their General project
+func (h *harness) startEntry(u testUser, projectID *uuid.UUID) models.TimeEntryResponse {
+	h.t.Helper()
+
+	rec := h.do(http.MethodPost, "/api/v1/time-entries", u, gin.H{"project_id": projectID})
+	h.expect(rec, http.StatusCreated)
+	return decode[models.TimeEntryResponse](h.t, rec)
+}
+
+// stopEntry stops one of the user's running time entries
+func (h *harness) stopEntry(u testUser, id uuid.UUID) models.TimeEntryResponse {
+	h.t.Helper()
+
+	rec := h.do(http.MethodPost, "/api/v1/time-entries/"+id.String()+"/stop", u, nil)
+	h.expect(rec, http.StatusOK)
+	return decode[models.TimeEntryResponse](h.t, rec)
+}
+
+// track records a stopped time entry of the given length on the current UTC day
+// The entry is started through the API and then moved back in time, so that it ends as late as
+// possible without crossing midnight and the tests never depend on the time of day
+func (h *harness) track(u testUser, projectID *uuid.UUID, length time.Duration) models.TimeEntryResponse {
+	h.t.Helper()
+
+	entry := h.startEntry(u, projectID)
+
+	now := time.Now().UTC()
+	start := now.Add(-length)
+	if midnight := now.Truncate(24 * time.Hour); start.Before(midnight) {
+		start = midnight
+	}
+	if err := h.db.Model(&models.TimeEntry{}).Where("id = ?", entry.ID).Update("start_time", start).Error; err != nil {
+		h.t.Fatalf("move time entry: %v", err)
+	}
+
+	endTime := start.Add(length).Format(time.RFC3339Nano)
+	rec := h.do(http.MethodPut, "/api/v1/time-entries/"+entry.ID.String(), u, gin.H{"end_time": endTime})
+	h.expect(rec, http.StatusOK)
+	return decode[models.TimeEntryResponse](h.t, rec)
+}
+
+func TestTimeEntryLifecycle(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+
+	entry := h.startEntry(alice, nil)
+	if entry.Project == nil || entry.Project.Name != models.GeneralProjectName {
+		t.Fatalf("expected the entry on the General project, got %+v", entry.Project)
+	}
+	if entry.EndTime != nil || entry.UserID != alice.ID {
+		t.Fatalf("expected a running entry for Alice, got %+v", entry)
+	}
+	path := "/api/v1/time-entries/" + entry.ID.String()
+
+	rec := h.do(http.MethodGet, path, alice, nil)
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.TimeEntryResponse](t, rec); got.ID != entry.ID {
+		t.Fatalf("expected entry %s, got %s", entry.ID, got.ID)
+	}
+
+	t.Run("end before start", func(t *testing.T) {
+		endTime := entry.StartTime.Add(-time.Hour).Format(time.RFC3339)
+		h.expectError(h.do(http.MethodPut, path, alice, gin.H{"end_time": endTime}), http.StatusBadRequest, "End time cannot be before start time")
+		h.expectError(h.doUnchecked(http.MethodPut, path, alice, gin.H{"end_time": "yesterday"}), http.StatusBadRequest, "Invalid end time format")
+	})
+
+	t.Run("unknown project", func(t *testing.T) {
+		h.expectError(h.do(http.MethodPut, path, alice, gin.H{"project_id": uuid.New()}), http.StatusBadRequest, "Project not found")
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		stopped := h.stopEntry(alice, entry.ID)
+		if stopped.EndTime == nil || stopped.Duration < 0 {
+			t.Fatalf("expected a stopped entry, got %+v", stopped)
+		}
+		h.expectError(h.do(http.MethodPost, path+"/stop", alice, nil), http.StatusBadRequest, "Time entry already stopped")
+	})
+
+	t.Run("set end time", func(t *testing.T) {
+		endTime := entry.StartTime.Add(90 * time.Minute).Truncate(time.Second)
+		rec := h.do(http.MethodPut, path, alice, gin.H{"end_time": endTime.Format(time.RFC3339)})
+		h.expect(rec, http.StatusOK)
+		updated := decode[models.TimeEntryResponse](t, rec)
+		if updated.EndTime == nil || !updated.EndTime.Equal(endTime) {
+			t.Fatalf("expected end time %s, got %v", endTime, updated.EndTime)
+		}
+		if want := int64(endTime.Sub(entry.StartTime).Seconds()); updated.Duration != want {
+			t.Fatalf("expected duration %d, got %d", want, updated.Duration)
+		}
+		// The first tracked session unlocks an achievement
+		if len(updated.UnlockedAchievements) != 1 || updated.UnlockedAchievements[0].Code != "first_entry" {
+			t.Fatalf("expected the first_entry achievement, got %+v", updated.UnlockedAchievements)
+		}
+	})
+
+	t.Run("move to project", func(t *testing.T) {
+		project := h.createProject(alice, gin.H{"name": "Website"})
+		rec := h.do(http.MethodPut, path, alice, gin.H{"project_id": project.ID})
+		h.expect(rec, http.StatusOK)
+		if got := decode[models.TimeEntryResponse](t, rec); got.Project == nil || got.Project.ID != project.ID {
+			t.Fatalf("expected the entry on project %s, got %+v", project.ID, got.Project)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		h.expect(h.do(http.MethodDelete, path, alice, nil), http.StatusOK)
+		h.expectError(h.do(http.MethodGet, path, alice, nil), http.StatusNotFound, "Time entry not found")
+		h.expectError(h.do(http.MethodDelete, path, alice, nil), http.StatusNotFound, "Time entry not found")
+	})
+}
+
+func TestTimeEntryWithoutProfile(t *testing.T) {
+	h := newHarness(t)
+	stranger := h.user("Stranger")
+
+	h.expectError(h.do(http.MethodPost, "/api/v1/time-entries", stranger, gin.H{}), http.StatusBadRequest, "General project not found. Please create a profile first.")
+}
+
+func TestTimeEntryOwnership(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+
+	entry := h.startEntry(alice, nil)
+	path := "/api/v1/time-entries/" + entry.ID.String()
+
+	h.expectError(h.do(http.MethodGet, path, bob, nil), http.StatusNotFound, "Time entry not found")
+	h.expectError(h.do(http.MethodPut, path, bob, gin.H{"end_time": time.Now().Format(time.RFC3339)}), http.StatusNotFound, "Time entry not found")
+	h.expectError(h.do(http.MethodPost, path+"/stop", bob, nil), http.StatusNotFound, "Time entry not found")
+	h.expectError(h.do(http.MethodDelete, path, bob, nil), http.StatusNotFound, "Time entry not found")
+
+	rec := h.do(http.MethodGet, "/api/v1/time-entries", bob, nil)
+	h.expect(rec, http.StatusOK)
+	if list := decode[models.PaginatedTimeEntriesResponse](t, rec); list.Total != 0 {
+		t.Fatalf("expected no entries for Bob, got %d", list.Total)
+	}
+
+	// Bob cannot track time on Alice's personal project either
+	project := h.createProject(alice, gin.H{"name": "Secret"})
+	h.expectError(h.do(http.MethodPost, "/api/v1/time-entries", bob, gin.H{"project_id": project.ID}), http.StatusBadRequest, "Project not found")
+
+	// Alice's entry is untouched
+	h.expect(h.do(http.MethodGet, path, alice, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodGet, "/api/v1/time-entries/not-a-uuid", alice, nil), http.StatusBadRequest, "Invalid ID")
+}
+
+func TestTimeEntryPagination(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+
+	var ids []uuid.UUID
+	for i := 0; i < 5; i++ {
+		entry := h.startEntry(alice, nil)
+		h.stopEntry(alice, entry.ID)
+		ids = append(ids, entry.ID)
+	}
+
+	rec := h.do(http.MethodGet, "/api/v1/time-entries?page=2&limit=2", alice, nil)
+	h.expect(rec, http.StatusOK)
+	page := decode[models.PaginatedTimeEntriesResponse](t, rec)
+	if page.Page != 2 || page.Limit != 2 || page.Total != 5 || page.TotalPages != 3 {
+		t.Fatalf("unexpected pagination %+v", page)
+	}
+	// Newest first: the second page holds the third and second newest entries
+	if len(page.Data) != 2 || page.Data[0].ID != ids[2] || page.Data[1].ID != ids[1] {
+		t.Fatalf("unexpected entries on page 2: %+v", page.Data)
+	}
+
+	rec = h.do(http.MethodGet, "/api/v1/time-entries?page=3&limit=2", alice, nil)
+	h.expect(rec, http.StatusOK)
+	if page := decode[models.PaginatedTimeEntriesResponse](t, rec); len(page.Data) != 1 || page.Data[0].ID != ids[0] {
+		t.Fatalf("expected the oldest entry on the last page, got %+v", page.Data)
+	}
+
+	// Out of range values fall back to the defaults
+	rec = h.doUnchecked(http.MethodGet, "/api/v1/time-entries?page=0&limit=1000", alice, nil)
+	h.expect(rec, http.StatusOK)
+	if page := decode[models.PaginatedTimeEntriesResponse](t, rec); page.Page != 1 || page.Limit > 100 {
+		t.Fatalf("expected clamped pagination, got page %d limit %d", page.Page, page.Limit)
+	}
+
+	h.expectError(h.do(http.MethodGet, "/api/v1/time-entries?workspace_id=nope", alice, nil), http.StatusBadRequest, "Invalid workspace ID")
+}
+
+func TestTimeEntryPeriodLock(t *testing.T) {
+	h := newHarness(t)
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+	admin := h.admin("Admin")
+
+	entry := h.startEntry(alice, nil)
+	path := "/api/v1/time-entries/" + entry.ID.String()
+
+	// Lock Alice's time up to and including today
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	h.expect(h.do(http.MethodPost, "/api/v1/admin/period-locks", admin, gin.H{"locked_before": tomorrow, "user_id": alice.ID}), http.StatusCreated)
+
+	const message = "Time entry is in a locked period"
+	h.expectError(h.do(http.MethodPost, "/api/v1/time-entries", alice, gin.H{}), http.StatusLocked, message)
+	h.expectError(h.do(http.MethodPut, path, alice, gin.H{"project_id": nil}), http.StatusLocked, message)
+	h.expectError(h.do(http.MethodDelete, path, alice, nil), http.StatusLocked, message)
+
+	// The running entry can still be stopped; the lock ends after now, so it ends now
+	if stopped := h.stopEntry(alice, entry.ID); stopped.EndTime == nil {
+		t.Fatalf("expected a stopped entry, got %+v", stopped)
+	}
+
+	// The lock only applies to Alice
+	h.startEntry(bob, nil)
+}
@@ -0,0 +1,186 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestTimesheetReview(t *testing.T) {
+	h := newHarness(t)
+	manager := h.signUp("Manager")
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+
+	workspace := h.createWorkspace(manager, "Acme")
+	h.join(manager, workspace.ID, alice, models.WorkspaceRoleMember)
+	h.join(manager, workspace.ID, bob, models.WorkspaceRoleMember)
+
+	// Only weeks that have ended can be submitted, so Alice's time goes into last week
+	entry := h.startEntry(alice, nil)
+	h.stopEntry(alice, entry.ID)
+	tracked := h.track(alice, nil, time.Hour)
+	h.moveEntry(entry.ID, -7*24*time.Hour)
+	h.moveEntry(tracked.ID, -7*24*time.Hour)
+
+	t.Run("submit", func(t *testing.T) {
+		h.expectError(h.doUnchecked(http.MethodPost, "/api/v1/timesheets/submit", alice, gin.H{"week_start": "last week"}), http.StatusBadRequest, "Invalid week_start format (use YYYY-MM-DD)")
+
+		running := h.startEntry(alice, nil)
+		h.moveEntry(running.ID, -7*24*time.Hour)
+		h.expectError(h.do(http.MethodPost, "/api/v1/timesheets/submit", alice, nil), http.StatusBadRequest, "Stop running time entries before submitting the week")
+		h.expect(h.do(http.MethodDelete, "/api/v1/time-entries/"+running.ID.String(), alice, nil), http.StatusOK)
+
+		// Profiles start weeks on Monday
+		thisMonday := time.Now().UTC()
+		for thisMonday.Weekday() != time.Monday {
+			thisMonday = thisMonday.AddDate(0, 0, -1)
+		}
+		const message = "Cannot submit a week that has not ended"
+		h.expectError(h.do(http.MethodPost, "/api/v1/timesheets/submit", alice, gin.H{"week_start": thisMonday.Format("2006-01-02")}), http.StatusBadRequest, message)
+		h.expectError(h.do(http.MethodPost, "/api/v1/timesheets/submit", alice, gin.H{"week_start": thisMonday.AddDate(0, 0, 7).Format("2006-01-02")}), http.StatusBadRequest, message)
+		h.expectError(h.do(http.MethodPost, "/api/v1/timesheets/submit", alice, gin.H{"week_start": thisMonday.AddDate(0, 0, -1).Format("2006-01-02")}), http.StatusBadRequest, "week_start must be the first day of a week")
+	})
+
+	rec := h.do(http.MethodPost, "/api/v1/timesheets/submit", alice, nil)
+	h.expect(rec, http.StatusCreated)
+	timesheet := decode[models.TimesheetResponse](t, rec)
+	if timesheet.Status != models.TimesheetSubmitted || timesheet.Entries != 2 || timesheet.TotalHours != 1 {
+		t.Fatalf("unexpected timesheet %+v", timesheet)
+	}
+	path := "/api/v1/timesheets/" + timesheet.ID.String()
+	h.expectError(h.do(http.MethodPost, "/api/v1/timesheets/submit", alice, nil), http.StatusConflict, "Timesheet already submitted")
+
+	t.Run("visibility", func(t *testing.T) {
+		rec := h.do(http.MethodGet, "/api/v1/timesheets", alice, nil)
+		h.expect(rec, http.StatusOK)
+		if list := decode[[]models.TimesheetResponse](t, rec); len(list) != 1 || list[0].ID != timesheet.ID || list[0].Entries != 2 || list[0].TotalHours != 1 {
+			t.Fatalf("expected Alice's timesheet with its totals, got %+v", list)
+		}
+		rec = h.do(http.MethodGet, "/api/v1/timesheets?status=approved", alice, nil)
+		h.expect(rec, http.StatusOK)
+		if list := decode[[]models.TimesheetResponse](t, rec); len(list) != 0 {
+			t.Fatalf("expected no approved timesheets, got %+v", list)
+		}
+
+		h.expect(h.do(http.MethodGet, path, manager, nil), http.StatusOK)
+		h.expectError(h.do(http.MethodGet, path, bob, nil), http.StatusNotFound, "Timesheet not found")
+		h.expectError(h.do(http.MethodGet, "/api/v1/timesheets/nope", alice, nil), http.StatusBadRequest, "Invalid ID")
+
+		rec = h.do(http.MethodGet, "/api/v1/timesheets/pending", manager, nil)
+		h.expect(rec, http.StatusOK)
+		if list := decode[[]models.TimesheetResponse](t, rec); len(list) != 1 || list[0].ID != timesheet.ID || list[0].Entries != 2 || list[0].TotalHours != 1 {
+			t.Fatalf("expected Alice's timesheet to review with its totals, got %+v", list)
+		}
+		rec = h.do(http.MethodGet, "/api/v1/timesheets/pending", bob, nil)
+		h.expect(rec, http.StatusOK)
+		if list := decode[[]models.TimesheetResponse](t, rec); len(list) != 0 {
+			t.Fatalf("expected nothing for Bob to review, got %+v", list)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		h.expectError(h.do(http.MethodPost, path+"/approve", alice, gin.H{}), http.StatusForbidden, "Cannot review your own timesheet")
+		h.expectError(h.do(http.MethodPost, path+"/approve", bob, gin.H{}), http.StatusNotFound, "Timesheet not found")
+		h.expectError(h.do(http.MethodPost, path+"/reject", manager, gin.H{}), http.StatusBadRequest, "A comment is required to reject a timesheet")
+
+		rec := h.do(http.MethodPost, path+"/reject", manager, gin.H{"comment": "Missing Friday"})
+		h.expect(rec, http.StatusOK)
+		if got := decode[models.TimesheetResponse](t, rec); got.Status != models.TimesheetRejected || got.Comment != "Missing Friday" {
+			t.Fatalf("unexpected timesheet %+v", got)
+		}
+		h.expectError(h.do(http.MethodPost, path+"/approve", manager, gin.H{}), http.StatusConflict, "Timesheet is rejected, not submitted")
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		// Rejected timesheets can be submitted again
+		h.expect(h.do(http.MethodPost, "/api/v1/timesheets/submit", alice, nil), http.StatusCreated)
+
+		rec := h.do(http.MethodPost, path+"/approve", manager, nil)
+		h.expect(rec, http.StatusOK)
+		if got := decode[models.TimesheetResponse](t, rec); got.Status != models.TimesheetApproved || got.ReviewedBy == nil || *got.ReviewedBy != manager.ID {
+			t.Fatalf("unexpected timesheet %+v", got)
+		}
+
+		rec = h.do(http.MethodGet, path, alice, nil)
+		h.expect(rec, http.StatusOK)
+		history := decode[models.TimesheetResponse](t, rec).Events
+		if len(history) != 4 || history[1].Action != models.TimesheetRejected || history[3].ActorID != manager.ID {
+			t.Fatalf("expected submit, reject, submit and approve events, got %+v", history)
+		}
+	})
+
+	t.Run("unfinished week", func(t *testing.T) {
+		// A week submitted before it ended cannot be reviewed until it has
+		early := models.Timesheet{UserID: bob.ID, WeekStart: time.Now().UTC().Truncate(24 * time.Hour), Status: models.TimesheetSubmitted, SubmittedAt: time.Now()}
+		if err := h.db.Create(&early).Error; err != nil {
+			t.Fatalf("create timesheet: %v", err)
+		}
+		h.expectError(h.do(http.MethodPost, "/api/v1/timesheets/"+early.ID.String()+"/approve", manager, nil), http.StatusBadRequest, "Cannot review a week that has not ended")
+	})
+
+	t.Run("approved time is locked", func(t *testing.T) {
+		const message = "Time entry is in an approved timesheet"
+		entryPath := "/api/v1/time-entries/" + entry.ID.String()
+		h.expectError(h.do(http.MethodPut, entryPath, alice, gin.H{"project_id": nil}), http.StatusLocked, message)
+		h.expectError(h.do(http.MethodDelete, entryPath, alice, nil), http.StatusLocked, message)
+		h.expectError(h.do(http.MethodPost, "/api/v1/timesheets/submit", alice, nil), http.StatusConflict, "Timesheet already approved")
+	})
+}
+
+// moveEntry shifts a time entry's start and end, as if it had been tracked earlier or later
+func (h *harness) moveEntry(id uuid.UUID, offset time.Duration) {
+	h.t.Helper()
+
+	var entry models.TimeEntry
+	if err := h.db.First(&entry, "id = ?", id).Error; err != nil {
+		h.t.Fatalf("find time entry: %v", err)
+	}
+	updates := map[string]interface{}{"start_time": entry.StartTime.Add(offset)}
+	if entry.EndTime != nil {
+		updates["end_time"] = entry.EndTime.Add(offset)
+	}
+	if err := h.db.Model(&entry).Updates(updates).Error; err != nil {
+		h.t.Fatalf("move time entry: %v", err)
+	}
+}
+
+func TestTimesheetApprovedOverRunningEntry(t *testing.T) {
+	h := newHarness(t)
+	manager := h.signUp("Manager")
+	alice := h.signUp("Alice")
+
+	workspace := h.createWorkspace(manager, "Acme")
+	h.join(manager, workspace.ID, alice, models.WorkspaceRoleMember)
+
+	entry := h.startEntry(alice, nil)
+
+	// Profiles start weeks on Monday
+	lastMonday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -7)
+	for lastMonday.Weekday() != time.Monday {
+		lastMonday = lastMonday.AddDate(0, 0, -1)
+	}
+	rec := h.do(http.MethodPost, "/api/v1/timesheets/submit", alice, gin.H{"week_start": lastMonday.Format("2006-01-02")})
+	h.expect(rec, http.StatusCreated)
+	timesheet := decode[models.TimesheetResponse](t, rec)
+	h.expect(h.do(http.MethodPost, "/api/v1/timesheets/"+timesheet.ID.String()+"/approve", manager, nil), http.StatusOK)
+
+	// The timer was started in the approved week and kept running past its end
+	if err := h.db.Model(&models.TimeEntry{}).Where("id = ?", entry.ID).Update("start_time", lastMonday.Add(9*time.Hour)).Error; err != nil {
+		t.Fatalf("move time entry: %v", err)
+	}
+
+	// Stopping it ends it with the approved week, after which it is locked like the rest of the week
+	stopped := h.stopEntry(alice, entry.ID)
+	if weekEnd := lastMonday.AddDate(0, 0, 7); stopped.EndTime == nil || !stopped.EndTime.Equal(weekEnd) {
+		t.Fatalf("expected the entry to end at %s, got %v", weekEnd, stopped.EndTime)
+	}
+	h.expectError(h.do(http.MethodDelete, "/api/v1/time-entries/"+entry.ID.String(), alice, nil), http.StatusLocked, "Time entry is in an approved timesheet")
+
+	// Time in the current week can still be tracked
+	h.startEntry(alice, nil)
+}
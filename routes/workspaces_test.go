@@ -0,0 +1,220 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+	"time-tracker/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// createWorkspace creates a workspace owned by the user
+func (h *harness) createWorkspace(owner testUser, name string) models.WorkspaceResponse {
+	h.t.Helper()
+
+	rec := h.do(http.MethodPost, "/api/v1/workspaces", owner, gin.H{"name": name})
+	h.expect(rec, http.StatusCreated)
+	return decode[models.WorkspaceResponse](h.t, rec)
+}
+
+// invite invites the user's email to the workspace and returns the accept token
+func (h *harness) invite(admin testUser, workspaceID uuid.UUID, u testUser, role string) string {
+	h.t.Helper()
+
+	rec := h.do(http.MethodPost, "/api/v1/workspaces/"+workspaceID.String()+"/invitations", admin, gin.H{"email": u.Email, "role": role})
+	h.expect(rec, http.StatusCreated)
+	return decode[models.WorkspaceInvitationResponse](h.t, rec).Token
+}
+
+// join adds the user to the workspace through an invitation they accept
+func (h *harness) join(admin testUser, workspaceID uuid.UUID, u testUser, role string) {
+	h.t.Helper()
+
+	token := h.invite(admin, workspaceID, u, role)
+	h.expect(h.do(http.MethodPost, "/api/v1/workspaces/invitations/accept", u, gin.H{"token": token}), http.StatusOK)
+}
+
+func TestWorkspaceLifecycle(t *testing.T) {
+	h := newHarness(t)
+	owner := h.signUp("Owner")
+	outsider := h.signUp("Outsider")
+
+	h.expect(h.do(http.MethodPost, "/api/v1/workspaces", owner, gin.H{}), http.StatusBadRequest)
+
+	workspace := h.createWorkspace(owner, "Acme")
+	if workspace.Role != models.WorkspaceRoleOwner {
+		t.Fatalf("expected the creator to own the workspace, got %q", workspace.Role)
+	}
+	path := "/api/v1/workspaces/" + workspace.ID.String()
+
+	rec := h.do(http.MethodGet, "/api/v1/workspaces", owner, nil)
+	h.expect(rec, http.StatusOK)
+	if list := decode[[]models.WorkspaceResponse](t, rec); len(list) != 1 || list[0].ID != workspace.ID {
+		t.Fatalf("expected the owner's workspace, got %+v", list)
+	}
+
+	rec = h.do(http.MethodGet, "/api/v1/workspaces", outsider, nil)
+	h.expect(rec, http.StatusOK)
+	if list := decode[[]models.WorkspaceResponse](t, rec); len(list) != 0 {
+		t.Fatalf("expected no workspaces for an outsider, got %+v", list)
+	}
+
+	h.expect(h.do(http.MethodGet, path, owner, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodGet, path, outsider, nil), http.StatusNotFound, "Workspace not found")
+	h.expectError(h.do(http.MethodGet, "/api/v1/workspaces/not-a-uuid", owner, nil), http.StatusBadRequest, "Invalid ID")
+
+	rec = h.do(http.MethodPut, path, owner, gin.H{"name": "Acme Inc", "leaderboard_visible": false})
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.WorkspaceResponse](t, rec); got.Name != "Acme Inc" || got.LeaderboardVisible {
+		t.Fatalf("unexpected workspace %+v", got)
+	}
+	h.expectError(h.do(http.MethodPut, path, outsider, gin.H{"name": "Mine"}), http.StatusNotFound, "Workspace not found")
+
+	// A workspace with projects cannot be deleted
+	project := h.createProject(owner, gin.H{"name": "Shared", "workspace_id": workspace.ID})
+	h.expectError(h.do(http.MethodDelete, path, owner, nil), http.StatusConflict, "Delete the workspace's projects first")
+	h.expect(h.do(http.MethodDelete, "/api/v1/projects/"+project.ID.String(), owner, nil), http.StatusOK)
+
+	h.expectError(h.do(http.MethodDelete, path, outsider, nil), http.StatusNotFound, "Workspace not found")
+	h.expect(h.do(http.MethodDelete, path, owner, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodGet, path, owner, nil), http.StatusNotFound, "Workspace not found")
+}
+
+func TestWorkspaceInvitations(t *testing.T) {
+	h := newHarness(t)
+	owner := h.signUp("Owner")
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+
+	workspace := h.createWorkspace(owner, "Acme")
+	invitations := "/api/v1/workspaces/" + workspace.ID.String() + "/invitations"
+
+	h.expect(h.do(http.MethodPost, invitations, owner, gin.H{"email": "not-an-email"}), http.StatusBadRequest)
+	h.expectError(h.doUnchecked(http.MethodPost, invitations, owner, gin.H{"email": alice.Email, "role": "owner"}), http.StatusBadRequest, "Key: 'WorkspaceInvitationRequest.Role' Error:Field validation for 'Role' failed on the 'oneof' tag")
+
+	token := h.invite(owner, workspace.ID, alice, models.WorkspaceRoleMember)
+	if token == "" {
+		t.Fatal("expected an invitation token")
+	}
+	h.expectError(h.do(http.MethodPost, invitations, alice, gin.H{"email": bob.Email}), http.StatusNotFound, "Workspace not found")
+
+	rec := h.do(http.MethodGet, invitations, owner, nil)
+	h.expect(rec, http.StatusOK)
+	pending := decode[[]models.WorkspaceInvitationResponse](t, rec)
+	if len(pending) != 1 || pending[0].Email != alice.Email || pending[0].Token != "" {
+		t.Fatalf("expected Alice's invitation without its token, got %+v", pending)
+	}
+
+	accept := "/api/v1/workspaces/invitations/accept"
+	h.expectError(h.do(http.MethodPost, accept, alice, gin.H{"token": "wrong"}), http.StatusNotFound, "Invitation not found or expired")
+	h.expectError(h.do(http.MethodPost, accept, bob, gin.H{"token": token}), http.StatusForbidden, "Invitation was sent to a different email address")
+
+	rec = h.do(http.MethodPost, accept, alice, gin.H{"token": token})
+	h.expect(rec, http.StatusOK)
+	if got := decode[models.WorkspaceResponse](t, rec); got.ID != workspace.ID || got.Role != models.WorkspaceRoleMember {
+		t.Fatalf("unexpected workspace %+v", got)
+	}
+	h.expectError(h.do(http.MethodPost, accept, alice, gin.H{"token": token}), http.StatusNotFound, "Invitation not found or expired")
+
+	// Members cannot be invited twice over
+	second := h.invite(owner, workspace.ID, alice, models.WorkspaceRoleViewer)
+	h.expectError(h.do(http.MethodPost, accept, alice, gin.H{"token": second}), http.StatusConflict, "Already a member of this workspace")
+
+	// Revoking
+	rec = h.do(http.MethodGet, invitations, owner, nil)
+	h.expect(rec, http.StatusOK)
+	pending = decode[[]models.WorkspaceInvitationResponse](t, rec)
+	if len(pending) != 1 {
+		t.Fatalf("expected one pending invitation, got %d", len(pending))
+	}
+	invitation := invitations + "/" + pending[0].ID.String()
+	h.expectError(h.do(http.MethodDelete, invitation, alice, nil), http.StatusForbidden, "Insufficient workspace role")
+	h.expectError(h.do(http.MethodDelete, invitations+"/nope", owner, nil), http.StatusBadRequest, "Invalid invitation ID")
+	h.expect(h.do(http.MethodDelete, invitation, owner, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodDelete, invitation, owner, nil), http.StatusNotFound, "Invitation not found")
+}
+
+func TestWorkspaceMembers(t *testing.T) {
+	h := newHarness(t)
+	owner := h.signUp("Owner")
+	admin := h.signUp("Admin")
+	member := h.signUp("Member")
+	outsider := h.signUp("Outsider")
+
+	workspace := h.createWorkspace(owner, "Acme")
+	h.join(owner, workspace.ID, admin, models.WorkspaceRoleAdmin)
+	h.join(admin, workspace.ID, member, models.WorkspaceRoleMember)
+	members := "/api/v1/workspaces/" + workspace.ID.String() + "/members"
+
+	rec := h.do(http.MethodGet, members, member, nil)
+	h.expect(rec, http.StatusOK)
+	list := decode[[]models.WorkspaceMemberResponse](t, rec)
+	if len(list) != 3 || list[0].UserID != owner.ID || list[0].Role != models.WorkspaceRoleOwner || list[2].Email != member.Email {
+		t.Fatalf("unexpected members %+v", list)
+	}
+	h.expectError(h.do(http.MethodGet, members, outsider, nil), http.StatusNotFound, "Workspace not found")
+
+	// Admins can change roles below their own
+	rec = h.do(http.MethodPut, members+"/"+member.ID.String(), admin, gin.H{"role": models.WorkspaceRoleViewer})
+	h.expect(rec, http.StatusOK)
+	if got := decode[gin.H](t, rec); got["role"] != models.WorkspaceRoleViewer || got["user_id"] != member.ID.String() {
+		t.Fatalf("unexpected member %+v", got)
+	}
+	h.expectError(h.do(http.MethodPut, members+"/"+member.ID.String(), admin, gin.H{"role": models.WorkspaceRoleAdmin}), http.StatusForbidden, "Insufficient workspace role")
+	h.expectError(h.do(http.MethodPut, members+"/"+owner.ID.String(), admin, gin.H{"role": models.WorkspaceRoleViewer}), http.StatusForbidden, "Insufficient workspace role")
+	h.expectError(h.do(http.MethodPut, members+"/"+member.ID.String(), member, gin.H{"role": models.WorkspaceRoleAdmin}), http.StatusForbidden, "Insufficient workspace role")
+	h.expectError(h.do(http.MethodPut, members+"/"+outsider.ID.String(), admin, gin.H{"role": models.WorkspaceRoleViewer}), http.StatusNotFound, "Member not found")
+	h.expectError(h.do(http.MethodPut, members+"/nope", admin, gin.H{"role": models.WorkspaceRoleViewer}), http.StatusBadRequest, "Invalid user ID")
+
+	// Removing members
+	h.expectError(h.do(http.MethodDelete, members+"/"+admin.ID.String(), member, nil), http.StatusForbidden, "Insufficient workspace role")
+	h.expectError(h.do(http.MethodDelete, members+"/"+owner.ID.String(), owner, nil), http.StatusBadRequest, "The owner cannot leave the workspace")
+	h.expect(h.do(http.MethodDelete, members+"/"+member.ID.String(), member, nil), http.StatusOK)
+	h.expect(h.do(http.MethodDelete, members+"/"+admin.ID.String(), owner, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodGet, members, admin, nil), http.StatusNotFound, "Workspace not found")
+}
+
+func TestWorkspaceTimeEntries(t *testing.T) {
+	h := newHarness(t)
+	owner := h.signUp("Owner")
+	alice := h.signUp("Alice")
+	bob := h.signUp("Bob")
+
+	workspace := h.createWorkspace(owner, "Acme")
+	h.join(owner, workspace.ID, alice, models.WorkspaceRoleMember)
+	h.join(owner, workspace.ID, bob, models.WorkspaceRoleMember)
+	project := h.createProject(owner, gin.H{"name": "Shared", "workspace_id": workspace.ID})
+
+	aliceEntry := h.startEntry(alice, &project.ID)
+	h.startEntry(bob, &project.ID)
+	h.startEntry(alice, nil) // Personal time stays out of the workspace
+
+	list := func(u testUser, query string) models.PaginatedTimeEntriesResponse {
+		t.Helper()
+		rec := h.do(http.MethodGet, "/api/v1/time-entries?workspace_id="+workspace.ID.String()+query, u, nil)
+		h.expect(rec, http.StatusOK)
+		return decode[models.PaginatedTimeEntriesResponse](t, rec)
+	}
+
+	// Admins see everyone's entries on the workspace, members only their own
+	if got := list(owner, ""); got.Total != 2 {
+		t.Fatalf("expected both members' entries for the owner, got %d", got.Total)
+	}
+	if got := list(owner, "&user_id="+alice.ID.String()); got.Total != 1 || got.Data[0].ID != aliceEntry.ID {
+		t.Fatalf("expected Alice's entry, got %+v", got.Data)
+	}
+	if got := list(bob, "&user_id="+alice.ID.String()); got.Total != 1 || got.Data[0].UserID != bob.ID {
+		t.Fatalf("expected only Bob's own entry, got %+v", got.Data)
+	}
+	h.expectError(h.do(http.MethodGet, "/api/v1/time-entries?workspace_id="+workspace.ID.String()+"&user_id=nope", owner, nil), http.StatusBadRequest, "Invalid user ID")
+
+	outsider := h.signUp("Outsider")
+	h.expectError(h.do(http.MethodGet, "/api/v1/time-entries?workspace_id="+workspace.ID.String(), outsider, nil), http.StatusNotFound, "Workspace not found")
+
+	// Admins can look at members' entries, but other members cannot
+	path := "/api/v1/time-entries/" + aliceEntry.ID.String()
+	h.expect(h.do(http.MethodGet, path, owner, nil), http.StatusOK)
+	h.expectError(h.do(http.MethodGet, path, bob, nil), http.StatusNotFound, "Time entry not found")
+	h.expectError(h.do(http.MethodPost, path+"/stop", owner, nil), http.StatusNotFound, "Time entry not found")
+}
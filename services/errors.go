@@ -0,0 +1,72 @@
+package services
+
+import "errors"
+
+// Kind classifies service errors so handlers can map them to HTTP statuses
+type Kind int
+
+const (
+	KindInvalid   Kind = iota + 1 // The request breaks a rule (400)
+	KindForbidden                 // The user's role does not allow it (403)
+	KindNotFound                  // The record does not exist or is not visible to the user (404)
+	KindConflict                  // The record is not in a state that allows it (409)
+	KindLocked                    // The time is in a closed period or approved timesheet (423)
+	KindInternal                  // A dependency failed (500)
+)
+
+// Error is a rule violation or failure with a message meant for the client
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error // Underlying cause, if any
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same kind and message, so sentinel errors work with errors.Is
+// even after a cause was attached with wrap
+func (e *Error) Is(target error) bool {
+	var other *Error
+	return errors.As(target, &other) && other.Kind == e.Kind && other.Message == e.Message
+}
+
+// wrap returns a copy of the error that records its cause
+func wrap(e *Error, err error) *Error {
+	return &Error{Kind: e.Kind, Message: e.Message, Err: err}
+}
+
+var (
+	ErrTimeEntryNotFound     = &Error{Kind: KindNotFound, Message: "Time entry not found"}
+	ErrTimeEntryStopped      = &Error{Kind: KindInvalid, Message: "Time entry already stopped"}
+	ErrInvalidEndTime        = &Error{Kind: KindInvalid, Message: "Invalid end time format"}
+	ErrEndBeforeStart        = &Error{Kind: KindInvalid, Message: "End time cannot be before start time"}
+	ErrGeneralProjectMissing = &Error{Kind: KindInvalid, Message: "General project not found. Please create a profile first."}
+	ErrPeriodLocked          = &Error{Kind: KindLocked, Message: "Time entry is in a locked period"}
+	ErrTimesheetApproved     = &Error{Kind: KindLocked, Message: "Time entry is in an approved timesheet"}
+
+	ErrProjectNotFound         = &Error{Kind: KindNotFound, Message: "Project not found"}
+	ErrProjectEntriesLocked    = &Error{Kind: KindConflict, Message: "Project has time entries in a locked period or approved timesheet"}
+	ErrTrackableProjectMissing = &Error{Kind: KindInvalid, Message: "Project not found"}
+	ErrReservedProjectName     = &Error{Kind: KindInvalid, Message: "Project name 'General' is reserved"}
+	ErrWorkspaceNotFound       = &Error{Kind: KindNotFound, Message: "Workspace not found"}
+	ErrInsufficientRole        = &Error{Kind: KindForbidden, Message: "Insufficient workspace role"}
+
+	ErrProfileNotFound      = &Error{Kind: KindNotFound, Message: "Profile not found"}
+	ErrUserNotFound         = &Error{Kind: KindNotFound, Message: "User not found"}
+	ErrProfileExists        = &Error{Kind: KindConflict, Message: "Profile already exists"}
+	ErrStatistics           = &Error{Kind: KindInternal, Message: "Failed to fetch statistics"}
+	ErrAchievements         = &Error{Kind: KindInternal, Message: "Failed to fetch achievements"}
+	ErrInvalidTimezone      = &Error{Kind: KindInvalid, Message: "Invalid timezone"}
+	ErrInvalidLocale        = &Error{Kind: KindInvalid, Message: "Invalid locale"}
+	ErrNoProfilePicture     = &Error{Kind: KindInvalid, Message: "No profile picture to delete"}
+	ErrPictureStorage       = &Error{Kind: KindInternal, Message: "Failed to delete profile picture from storage"}
+	ErrDeletionNotConfirmed = &Error{Kind: KindInvalid, Message: "Account deletion must be confirmed with \"DELETE\""}
+	ErrDeletionScheduled    = &Error{Kind: KindConflict, Message: "Account deletion already scheduled"}
+	ErrNoDeletionScheduled  = &Error{Kind: KindInvalid, Message: "No account deletion scheduled"}
+)
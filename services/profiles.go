@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"mime/multipart"
+	"time"
+	"time-tracker/achievements"
+	"time-tracker/levels"
+	"time-tracker/models"
+	"time-tracker/repository"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+)
+
+// PictureStorage stores profile pictures
+type PictureStorage interface {
+	// UploadProfilePicture stores the file on behalf of the user and returns its public URL
+	UploadProfilePicture(userID uuid.UUID, file multipart.File, header *multipart.FileHeader, userToken string) (string, error)
+	DeleteProfilePicture(publicURL string) error
+}
+
+// ProfileService holds the rules for profiles and the statistics shown on them
+type ProfileService struct {
+	profiles repository.ProfileRepository
+	projects repository.ProjectRepository
+	storage  PictureStorage
+
+	// GracePeriod is how long a scheduled account deletion can still be cancelled
+	GracePeriod time.Duration
+	// Now returns the current time; replace it to control the clock in tests
+	Now func() time.Time
+}
+
+// NewProfileService returns a profile service using the repositories and picture storage
+func NewProfileService(profiles repository.ProfileRepository, projects repository.ProjectRepository, storage PictureStorage, gracePeriod time.Duration) *ProfileService {
+	return &ProfileService{
+		profiles:    profiles,
+		projects:    projects,
+		storage:     storage,
+		GracePeriod: gracePeriod,
+		Now:         time.Now,
+	}
+}
+
+// Create creates the user's profile along with their "General" project
+func (s *ProfileService) Create(ctx context.Context, userID uuid.UUID, req models.CreateProfileRequest) (models.Profile, error) {
+	if _, err := s.profiles.Find(ctx, userID); err == nil {
+		return models.Profile{}, ErrProfileExists
+	}
+
+	profile := models.Profile{
+		ID:   userID,
+		Name: req.Name,
+	}
+	if err := s.profiles.Create(ctx, &profile); err != nil {
+		return models.Profile{}, err
+	}
+
+	general := models.Project{
+		UserID:      userID,
+		Name:        models.GeneralProjectName,
+		Description: "Default project for unassigned time entries",
+		Color:       models.DefaultProjectColor,
+	}
+	if err := s.projects.Create(ctx, &general); err != nil {
+		// Log error but don't fail profile creation
+		log.Printf("Failed to create general project for user %s: %v", userID, err)
+	}
+
+	return profile, nil
+}
+
+// Get returns the user's profile with their totals, streaks, level, rank and achievements
+func (s *ProfileService) Get(ctx context.Context, userID uuid.UUID) (models.UserResponse, error) {
+	user, err := s.profiles.FindUser(ctx, userID)
+	if err != nil {
+		return models.UserResponse{}, ErrUserNotFound
+	}
+	profile, err := s.find(ctx, userID)
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+
+	totals, err := s.profiles.Totals(ctx, profile.ID)
+	if err != nil {
+		return models.UserResponse{}, wrap(ErrStatistics, err)
+	}
+	streaks, err := s.profiles.Streaks(ctx, profile, s.Now())
+	if err != nil {
+		return models.UserResponse{}, wrap(ErrStatistics, err)
+	}
+
+	totalHours := float32(totals.Seconds) / 60 / 60
+
+	var dayilyAvg float32
+	if streaks.ActiveDays > 0 {
+		dayilyAvg = totalHours / float32(streaks.ActiveDays)
+	}
+
+	// Rank among all users, unless the user opted out of ranking
+	rank := 0
+	if profile.Visibility != models.VisibilityHidden {
+		if r, err := s.profiles.Rank(ctx, profile.ID); err == nil {
+			rank = r
+		}
+	}
+
+	// The profile holds the name users can change, auth.users only the one given at sign-up
+	name := profile.Name
+	if name == "" {
+		name = user.Name
+	}
+
+	response := models.UserResponse{
+		ID:                user.ID,
+		Name:              name,
+		Email:             user.Email,
+		ProfilePictureURL: profile.ProfilePictureURL,
+		Bio:               profile.Bio,
+		Timezone:          profile.Timezone,
+		WeekStartDay:      profile.WeekStartDay,
+		Locale:            profile.Locale,
+		TimeFormat:        profile.TimeFormat,
+		Visibility:        profile.Visibility,
+		TotalHours:        round1(totalHours),
+		TotalSessions:     totals.Sessions,
+		CurrentStreak:     streaks.CurrentStreak,
+		LongestStreak:     streaks.LongestStreak,
+		DayilyAvg:         round1(dayilyAvg),
+		Rank:              rank,
+		LevelProgress:     levels.Progress(s.profiles.LevelTiers(ctx), totalHours),
+		CreatedAt:         profile.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	owned, err := s.profiles.Achievements(ctx, profile.ID)
+	if err != nil {
+		return models.UserResponse{}, wrap(ErrAchievements, err)
+	}
+	response.Achievements = make([]models.AchievementResponse, 0, len(owned))
+	for _, userAchievement := range owned {
+		achievement := achievements.ToResponse(userAchievement.Achievement)
+		unlockedAt := userAchievement.UnlockedAt
+		achievement.Unlocked = true
+		achievement.UnlockedAt = &unlockedAt
+		response.Achievements = append(response.Achievements, achievement)
+	}
+
+	if streaks.LongestStart != nil {
+		start := streaks.LongestStart.Format("2006-01-02")
+		end := streaks.LongestEnd.Format("2006-01-02")
+		response.LongestStreakStart = &start
+		response.LongestStreakEnd = &end
+	}
+
+	return response, nil
+}
+
+// Update changes the user's name, bio and preferences and returns the stored profile
+func (s *ProfileService) Update(ctx context.Context, userID uuid.UUID, req models.UpdateProfileRequest) (models.Profile, error) {
+	profile, err := s.find(ctx, userID)
+	if err != nil {
+		return models.Profile{}, err
+	}
+
+	// Collect changed fields in a map so zero values (e.g. Sunday = 0) are saved
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Bio != nil {
+		updates["bio"] = *req.Bio
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return models.Profile{}, ErrInvalidTimezone
+		}
+		updates["timezone"] = *req.Timezone
+	}
+	if req.WeekStartDay != nil {
+		updates["week_start_day"] = *req.WeekStartDay
+	}
+	if req.Locale != nil {
+		tag, err := language.Parse(*req.Locale)
+		if err != nil {
+			return models.Profile{}, ErrInvalidLocale
+		}
+		updates["locale"] = tag.String()
+	}
+	if req.TimeFormat != nil {
+		updates["time_format"] = *req.TimeFormat
+	}
+	if req.Visibility != nil {
+		updates["leaderboard_visibility"] = *req.Visibility
+	}
+
+	if len(updates) == 0 {
+		return profile, nil
+	}
+	updates["updated_at"] = s.Now()
+	return s.profiles.Update(ctx, profile, updates)
+}
+
+// UploadPicture replaces the user's profile picture and returns its public URL
+func (s *ProfileService) UploadPicture(ctx context.Context, userID uuid.UUID, file multipart.File, header *multipart.FileHeader, userToken string) (string, error) {
+	profile, err := s.find(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if profile.ProfilePictureURL != nil && *profile.ProfilePictureURL != "" {
+		if err := s.storage.DeleteProfilePicture(*profile.ProfilePictureURL); err != nil {
+			// Log error but don't fail the upload
+			log.Printf("Failed to delete old profile picture: %v\n", err)
+		}
+	}
+
+	publicURL, err := s.storage.UploadProfilePicture(userID, file, header, userToken)
+	if err != nil {
+		return "", &Error{Kind: KindInternal, Message: err.Error(), Err: err}
+	}
+
+	if _, err := s.profiles.Update(ctx, profile, map[string]interface{}{"profile_picture_url": publicURL}); err != nil {
+		// Don't leave the uploaded file behind
+		s.storage.DeleteProfilePicture(publicURL)
+		return "", err
+	}
+	return publicURL, nil
+}
+
+// DeletePicture removes the user's profile picture from storage and their profile
+func (s *ProfileService) DeletePicture(ctx context.Context, userID uuid.UUID) error {
+	profile, err := s.find(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if profile.ProfilePictureURL == nil || *profile.ProfilePictureURL == "" {
+		return ErrNoProfilePicture
+	}
+
+	if err := s.storage.DeleteProfilePicture(*profile.ProfilePictureURL); err != nil {
+		return wrap(ErrPictureStorage, err)
+	}
+
+	_, err = s.profiles.Update(ctx, profile, map[string]interface{}{"profile_picture_url": nil})
+	return err
+}
+
+// ScheduleDeletion schedules the user's account for deletion once the grace period ends
+// The deletion can be cancelled with CancelDeletion until then
+func (s *ProfileService) ScheduleDeletion(ctx context.Context, userID uuid.UUID, req models.DeleteAccountRequest) (time.Time, error) {
+	if req.Confirm != "DELETE" {
+		return time.Time{}, ErrDeletionNotConfirmed
+	}
+
+	profile, err := s.find(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if profile.DeletionScheduledAt != nil {
+		return time.Time{}, ErrDeletionScheduled
+	}
+
+	scheduledAt := s.Now().Add(s.GracePeriod)
+	if _, err := s.profiles.Update(ctx, profile, map[string]interface{}{"deletion_scheduled_at": scheduledAt}); err != nil {
+		return time.Time{}, err
+	}
+	return scheduledAt, nil
+}
+
+// CancelDeletion cancels a pending account deletion
+func (s *ProfileService) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	profile, err := s.find(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if profile.DeletionScheduledAt == nil {
+		return ErrNoDeletionScheduled
+	}
+
+	_, err = s.profiles.Update(ctx, profile, map[string]interface{}{"deletion_scheduled_at": nil})
+	return err
+}
+
+func (s *ProfileService) find(ctx context.Context, userID uuid.UUID) (models.Profile, error) {
+	profile, err := s.profiles.Find(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.Profile{}, ErrProfileNotFound
+	}
+	return profile, err
+}
+
+// round1 rounds to one decimal place
+func round1(value float32) float32 {
+	return float32(int(value*10+0.5)) / 10
+}
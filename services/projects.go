@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time-tracker/models"
+	"time-tracker/repository"
+	"time-tracker/workspaces"
+
+	"github.com/google/uuid"
+)
+
+// ProjectService holds the rules for personal and shared projects
+type ProjectService struct {
+	projects repository.ProjectRepository
+}
+
+// NewProjectService returns a project service using the repository
+func NewProjectService(projects repository.ProjectRepository) *ProjectService {
+	return &ProjectService{projects: projects}
+}
+
+// Create adds a personal project, or a shared one when the request names a workspace
+// Only workspace admins can add shared projects, and "General" is reserved
+func (s *ProjectService) Create(ctx context.Context, userID uuid.UUID, req models.ProjectCreateRequest) (models.Project, error) {
+	if req.Name == models.GeneralProjectName {
+		return models.Project{}, ErrReservedProjectName
+	}
+
+	if req.WorkspaceID != nil {
+		if _, err := requireWorkspaceRole(ctx, s.projects, *req.WorkspaceID, userID, models.WorkspaceRoleAdmin); err != nil {
+			return models.Project{}, err
+		}
+	}
+
+	color := req.Color
+	if color == "" {
+		color = models.DefaultProjectColor
+	}
+
+	project := models.Project{
+		UserID:      userID,
+		WorkspaceID: req.WorkspaceID,
+		Name:        req.Name,
+		Description: req.Description,
+		Color:       color,
+	}
+	if err := s.projects.Create(ctx, &project); err != nil {
+		return models.Project{}, err
+	}
+	return project, nil
+}
+
+// List returns a page of the user's personal projects and the projects shared with them
+func (s *ProjectService) List(ctx context.Context, userID uuid.UUID, workspaceID *uuid.UUID, page repository.Page) ([]models.Project, int64, error) {
+	return s.projects.ListAccessible(ctx, userID, workspaceID, page)
+}
+
+// Get returns a project the user can access
+func (s *ProjectService) Get(ctx context.Context, userID, id uuid.UUID) (models.Project, error) {
+	project, err := s.projects.FindAccessible(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.Project{}, ErrProjectNotFound
+	}
+	return project, err
+}
+
+// Update changes a project the user manages; empty fields are left unchanged
+func (s *ProjectService) Update(ctx context.Context, userID, id uuid.UUID, req models.ProjectUpdateRequest) (models.Project, error) {
+	project, err := s.manageable(ctx, userID, id)
+	if err != nil {
+		return models.Project{}, err
+	}
+	previous := project
+
+	if req.Name != "" {
+		if req.Name == models.GeneralProjectName {
+			return models.Project{}, ErrReservedProjectName
+		}
+		project.Name = req.Name
+	}
+	if req.Description != "" {
+		project.Description = req.Description
+	}
+	if req.Color != "" {
+		project.Color = req.Color
+	}
+
+	if err := s.projects.Update(ctx, previous, &project); err != nil {
+		return models.Project{}, err
+	}
+	return project, nil
+}
+
+// Delete removes a project the user manages; its time entries are kept without a project
+// Projects with locked time entries cannot be deleted, since that would change those entries
+func (s *ProjectService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	project, err := s.manageable(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	err = s.projects.Delete(ctx, project)
+	if errors.Is(err, repository.ErrLocked) {
+		return ErrProjectEntriesLocked
+	}
+	return err
+}
+
+// CheckTrackable checks that the user may track time on the project
+func (s *ProjectService) CheckTrackable(ctx context.Context, userID uuid.UUID, projectID *uuid.UUID) error {
+	return CheckTrackableProject(ctx, s.projects, userID, projectID)
+}
+
+// manageable returns a project the user may edit or delete:
+// the owner of a personal project or an admin of the project's workspace
+func (s *ProjectService) manageable(ctx context.Context, userID, id uuid.UUID) (models.Project, error) {
+	project, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	role, err := s.projects.ProjectRole(ctx, project, userID)
+	if err != nil {
+		return models.Project{}, err
+	}
+	if !workspaces.AtLeast(role, models.WorkspaceRoleAdmin) {
+		return models.Project{}, ErrInsufficientRole
+	}
+	return project, nil
+}
+
+// CheckTrackableProject checks that the user may track time on the project:
+// one of their personal projects or a workspace project where they are at least a member
+// A nil project is always trackable
+func CheckTrackableProject(ctx context.Context, projects repository.ProjectRepository, userID uuid.UUID, projectID *uuid.UUID) error {
+	if projectID == nil {
+		return nil
+	}
+
+	project, err := projects.FindAccessible(ctx, userID, *projectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTrackableProjectMissing
+	}
+	if err != nil {
+		return err
+	}
+
+	role, err := projects.ProjectRole(ctx, project, userID)
+	if err != nil {
+		return err
+	}
+	if !workspaces.AtLeast(role, models.WorkspaceRoleMember) {
+		return ErrInsufficientRole
+	}
+	return nil
+}
+
+// RequireWorkspaceRole returns the user's role in the workspace if it is at least min
+// Non-members get ErrWorkspaceNotFound so workspace IDs are not revealed; members below the role get ErrInsufficientRole
+func (s *ProjectService) RequireWorkspaceRole(ctx context.Context, workspaceID, userID uuid.UUID, min string) (string, error) {
+	return requireWorkspaceRole(ctx, s.projects, workspaceID, userID, min)
+}
+
+// requireWorkspaceRole returns the user's workspace role if it is at least min
+func requireWorkspaceRole(ctx context.Context, projects repository.ProjectRepository, workspaceID, userID uuid.UUID, min string) (string, error) {
+	role, err := projects.WorkspaceRole(ctx, workspaceID, userID)
+	if err != nil {
+		return "", err
+	}
+	if role == "" {
+		return "", ErrWorkspaceNotFound
+	}
+	if !workspaces.AtLeast(role, min) {
+		return "", ErrInsufficientRole
+	}
+	return role, nil
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"time-tracker/models"
+	"time-tracker/repository"
+	"time-tracker/workspaces"
+
+	"github.com/google/uuid"
+)
+
+// TimeEntryService holds the rules for tracking time
+type TimeEntryService struct {
+	entries      repository.TimeEntryRepository
+	projects     repository.ProjectRepository
+	achievements repository.AchievementRepository
+
+	// Now returns the current time; replace it to control the clock in tests
+	Now func() time.Time
+}
+
+// NewTimeEntryService returns a time entry service using the repositories
+func NewTimeEntryService(entries repository.TimeEntryRepository, projects repository.ProjectRepository, achievements repository.AchievementRepository) *TimeEntryService {
+	return &TimeEntryService{
+		entries:      entries,
+		projects:     projects,
+		achievements: achievements,
+		Now:          time.Now,
+	}
+}
+
+// TimeEntryListOptions selects the entries to list
+// With a workspace, entries on the workspace's projects are listed: everyone's for admins
+// (optionally only MemberID's), the caller's own otherwise
+type TimeEntryListOptions struct {
+	WorkspaceID *uuid.UUID
+	MemberID    *uuid.UUID
+	Page        repository.Page
+}
+
+// Start starts a running entry now, on the project or the user's General project
+func (s *TimeEntryService) Start(ctx context.Context, userID uuid.UUID, projectID *uuid.UUID) (models.TimeEntry, error) {
+	now := s.Now()
+
+	if err := s.checkUnlocked(ctx, models.TimeEntry{UserID: userID, StartTime: now}); err != nil {
+		return models.TimeEntry{}, err
+	}
+	if err := CheckTrackableProject(ctx, s.projects, userID, projectID); err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	// Without a project, time goes to the user's "General" project
+	if projectID == nil {
+		general, err := s.projects.FindGeneral(ctx, userID)
+		if err != nil {
+			return models.TimeEntry{}, wrap(ErrGeneralProjectMissing, err)
+		}
+		projectID = &general.ID
+	}
+
+	entry := models.TimeEntry{
+		UserID:    userID,
+		ProjectID: projectID,
+		StartTime: now,
+	}
+	if err := s.entries.Create(ctx, &entry); err != nil {
+		return models.TimeEntry{}, err
+	}
+	return s.entries.FindWithProject(ctx, entry.ID)
+}
+
+// List returns a page of entries, newest first, and the total count
+func (s *TimeEntryService) List(ctx context.Context, userID uuid.UUID, opts TimeEntryListOptions) ([]models.TimeEntry, int64, error) {
+	filter := repository.TimeEntryFilter{UserID: &userID}
+
+	if opts.WorkspaceID != nil {
+		role, err := requireWorkspaceRole(ctx, s.projects, *opts.WorkspaceID, userID, models.WorkspaceRoleViewer)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		filter.WorkspaceID = opts.WorkspaceID
+		if workspaces.AtLeast(role, models.WorkspaceRoleAdmin) {
+			filter.UserID = opts.MemberID
+		}
+	}
+
+	return s.entries.List(ctx, filter, opts.Page)
+}
+
+// Get returns an entry the user owns, or one on a project of a workspace they manage
+func (s *TimeEntryService) Get(ctx context.Context, userID, id uuid.UUID) (models.TimeEntry, error) {
+	entry, err := s.entries.FindVisible(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.TimeEntry{}, ErrTimeEntryNotFound
+	}
+	return entry, err
+}
+
+// Update moves one of the user's entries to another project and/or sets its end time
+// Setting an end time stops the entry, so newly earned achievements are returned too
+func (s *TimeEntryService) Update(ctx context.Context, userID, id uuid.UUID, req models.TimeEntryUpdateRequest) (models.TimeEntry, []models.Achievement, error) {
+	entry, err := s.findUnlocked(ctx, userID, id)
+	if err != nil {
+		return models.TimeEntry{}, nil, err
+	}
+	previous := entry
+
+	if err := CheckTrackableProject(ctx, s.projects, userID, req.ProjectID); err != nil {
+		return models.TimeEntry{}, nil, err
+	}
+
+	if req.ProjectID != nil {
+		entry.ProjectID = req.ProjectID
+	}
+	if req.EndTime != "" {
+		endTime, err := time.Parse(time.RFC3339, req.EndTime)
+		if err != nil {
+			return models.TimeEntry{}, nil, ErrInvalidEndTime
+		}
+		if err := setEndTime(&entry, endTime); err != nil {
+			return models.TimeEntry{}, nil, err
+		}
+	}
+
+	if err := s.entries.Update(ctx, previous, &entry); err != nil {
+		return models.TimeEntry{}, nil, err
+	}
+
+	var unlocked []models.Achievement
+	if req.EndTime != "" {
+		unlocked = s.evaluateAchievements(ctx, entry)
+	}
+
+	entry, err = s.entries.FindWithProject(ctx, entry.ID)
+	return entry, unlocked, err
+}
+
+// Stop ends one of the user's running entries now and returns newly earned achievements
+// A running entry can be stopped even after its time was locked, but then ends where the lock does
+func (s *TimeEntryService) Stop(ctx context.Context, userID, id uuid.UUID) (models.TimeEntry, []models.Achievement, error) {
+	entry, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return models.TimeEntry{}, nil, err
+	}
+	if entry.EndTime != nil {
+		return models.TimeEntry{}, nil, ErrTimeEntryStopped
+	}
+
+	endTime := s.Now()
+	until, locked, err := s.entries.LockedUntil(ctx, entry)
+	if err != nil {
+		return models.TimeEntry{}, nil, err
+	}
+	if locked && endTime.After(until) {
+		endTime = until
+	}
+
+	previous := entry
+	if err := setEndTime(&entry, endTime); err != nil {
+		return models.TimeEntry{}, nil, err
+	}
+	if err := s.entries.Update(ctx, previous, &entry); err != nil {
+		return models.TimeEntry{}, nil, err
+	}
+
+	unlocked := s.evaluateAchievements(ctx, entry)
+
+	entry, err = s.entries.FindWithProject(ctx, entry.ID)
+	return entry, unlocked, err
+}
+
+// Delete removes one of the user's entries
+func (s *TimeEntryService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	entry, err := s.findUnlocked(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	return s.entries.Delete(ctx, entry)
+}
+
+// setEndTime ends the entry and computes its duration in whole seconds
+func setEndTime(entry *models.TimeEntry, endTime time.Time) error {
+	if endTime.Before(entry.StartTime) {
+		return ErrEndBeforeStart
+	}
+	entry.EndTime = &endTime
+	entry.Duration = int64(endTime.Sub(entry.StartTime).Seconds())
+	return nil
+}
+
+func (s *TimeEntryService) findOwned(ctx context.Context, userID, id uuid.UUID) (models.TimeEntry, error) {
+	entry, err := s.entries.FindOwned(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.TimeEntry{}, ErrTimeEntryNotFound
+	}
+	return entry, err
+}
+
+// findUnlocked returns one of the user's entries if it may still be changed
+func (s *TimeEntryService) findUnlocked(ctx context.Context, userID, id uuid.UUID) (models.TimeEntry, error) {
+	entry, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+	if err := s.checkUnlocked(ctx, entry); err != nil {
+		return models.TimeEntry{}, err
+	}
+	return entry, nil
+}
+
+// checkUnlocked rejects changes to entries in locked periods or approved timesheets
+func (s *TimeEntryService) checkUnlocked(ctx context.Context, entry models.TimeEntry) error {
+	if err := s.checkPeriodUnlocked(ctx, entry.UserID, entry.StartTime); err != nil {
+		return err
+	}
+
+	locked, err := s.entries.InApprovedTimesheet(ctx, entry)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrTimesheetApproved
+	}
+	return nil
+}
+
+// checkPeriodUnlocked rejects time on days an admin closed
+func (s *TimeEntryService) checkPeriodUnlocked(ctx context.Context, userID uuid.UUID, t time.Time) error {
+	locked, err := s.entries.PeriodLocked(ctx, userID, t)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrPeriodLocked
+	}
+	return nil
+}
+
+// evaluateAchievements unlocks achievements earned by stopping the entry
+// Failures are logged rather than returned so they never undo the stop itself
+func (s *TimeEntryService) evaluateAchievements(ctx context.Context, entry models.TimeEntry) []models.Achievement {
+	unlocked, err := s.achievements.Evaluate(ctx, entry)
+	if err != nil {
+		log.Printf("Failed to evaluate achievements for user %s: %v", entry.UserID, err)
+		return nil
+	}
+	return unlocked
+}
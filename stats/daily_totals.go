@@ -0,0 +1,159 @@
+package stats
+
+import (
+	"log"
+	"time"
+	"time-tracker/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DateFormat is the layout used for local calendar dates
+const DateFormat = "2006-01-02"
+
+// dayShare is the part of a time entry that falls on one local calendar day
+type dayShare struct {
+	Date     time.Time
+	Seconds  int64
+	Sessions int
+}
+
+// LocalDate returns the calendar date of t in loc as midnight UTC,
+// so consecutive dates are always exactly one AddDate(0, 0, 1) apart regardless of DST
+func LocalDate(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// UserLocation returns the timezone from the user's profile, or UTC if they have none
+func UserLocation(tx *gorm.DB, userID uuid.UUID) *time.Location {
+	var profile models.Profile
+	if err := tx.Where("id = ?", userID).First(&profile).Error; err != nil {
+		return time.UTC
+	}
+	return profile.Location()
+}
+
+// splitEntry divides a stopped entry into per-day shares in loc
+// Entries crossing midnight contribute to every day they touch; the session is counted on the start day
+func splitEntry(entry models.TimeEntry, loc *time.Location) []dayShare {
+	if entry.EndTime == nil || entry.Duration <= 0 || entry.DeletedAt.Valid {
+		return nil
+	}
+
+	var shares []dayShare
+	start, end := entry.StartTime, *entry.EndTime
+	sessions := 1
+	for start.Before(end) {
+		date := LocalDate(start, loc)
+		local := start.In(loc)
+		nextMidnight := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+		if nextMidnight.After(end) {
+			nextMidnight = end
+		}
+
+		shares = append(shares, dayShare{
+			Date:     date,
+			Seconds:  int64(nextMidnight.Sub(start).Seconds()),
+			Sessions: sessions,
+		})
+		sessions = 0
+		start = nextMidnight
+	}
+
+	if len(shares) == 0 {
+		return nil
+	}
+
+	// Give rounding leftovers to the last day so the shares add up to the entry's duration
+	var assigned int64
+	for _, share := range shares[:len(shares)-1] {
+		assigned += share.Seconds
+	}
+	shares[len(shares)-1].Seconds = entry.Duration - assigned
+	return shares
+}
+
+// AddEntry adds a time entry's contribution to the daily totals
+// Call it inside the transaction that creates, stops or updates the entry
+func AddEntry(tx *gorm.DB, entry models.TimeEntry) error {
+	return applyEntry(tx, entry, UserLocation(tx, entry.UserID), 1)
+}
+
+// RemoveEntry subtracts a time entry's contribution from the daily totals
+// Call it with the entry as it was before an update or delete, inside the same transaction
+func RemoveEntry(tx *gorm.DB, entry models.TimeEntry) error {
+	if err := applyEntry(tx, entry, UserLocation(tx, entry.UserID), -1); err != nil {
+		return err
+	}
+
+	return tx.Where("user_id = ? AND seconds <= 0 AND sessions <= 0", entry.UserID).Delete(&models.DailyUserTotal{}).Error
+}
+
+func applyEntry(tx *gorm.DB, entry models.TimeEntry, loc *time.Location, sign int) error {
+	projectID := uuid.Nil
+	if entry.ProjectID != nil {
+		projectID = *entry.ProjectID
+	}
+
+	for _, share := range splitEntry(entry, loc) {
+		// Dates are passed as strings so the database session timezone cannot shift them
+		err := tx.Exec(`
+			INSERT INTO daily_user_totals (user_id, local_date, project_id, seconds, sessions)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (user_id, local_date, project_id)
+			DO UPDATE SET seconds = daily_user_totals.seconds + EXCLUDED.seconds,
+				sessions = daily_user_totals.sessions + EXCLUDED.sessions
+		`, entry.UserID, share.Date.Format(DateFormat), projectID, int64(sign)*share.Seconds, sign*share.Sessions).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RebuildUser recomputes all daily totals of one user from their time entries
+// Needed after changes that affect every entry, such as a timezone change
+func RebuildUser(tx *gorm.DB, userID uuid.UUID) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.DailyUserTotal{}).Error; err != nil {
+		return err
+	}
+
+	var entries []models.TimeEntry
+	if err := tx.Where("user_id = ? AND duration > ? AND end_time IS NOT NULL", userID, 0).Find(&entries).Error; err != nil {
+		return err
+	}
+
+	loc := UserLocation(tx, userID)
+	for _, entry := range entries {
+		if err := applyEntry(tx, entry, loc, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RebuildAll recomputes the daily totals of every user with time entries
+func RebuildAll(db *gorm.DB) error {
+	var userIDs []uuid.UUID
+	if err := db.Model(&models.TimeEntry{}).Distinct("user_id").Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	// Drop totals of users who no longer have any entries
+	if err := db.Where("user_id NOT IN (?)", db.Model(&models.TimeEntry{}).Select("user_id")).Delete(&models.DailyUserTotal{}).Error; err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return RebuildUser(tx, userID)
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("Rebuilt daily totals for user %s", userID)
+	}
+	return nil
+}
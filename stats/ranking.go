@@ -0,0 +1,65 @@
+package stats
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// rankedUsersSQL ranks users by time tracked on or after a given local date
+// Tied users share a rank (matching the COUNT(*) + 1 semantics of a "users ahead of me" query),
+// while position breaks ties by user_id so pagination is deterministic.
+// Users who hid themselves from the leaderboard are not ranked at all
+const rankedUsersSQL = `
+	SELECT user_id,
+		total_seconds / 3600.0 AS total_hours,
+		RANK() OVER (ORDER BY total_seconds DESC) AS rank,
+		ROW_NUMBER() OVER (ORDER BY total_seconds DESC, user_id) AS position
+	FROM (
+		SELECT user_id, SUM(seconds) AS total_seconds
+		FROM daily_user_totals
+		WHERE local_date >= ?
+			AND user_id NOT IN (SELECT id FROM profiles WHERE leaderboard_visibility = 'hidden')
+			%s
+		GROUP BY user_id
+		HAVING SUM(seconds) > 0
+	) totals
+`
+
+// RankedUsersQuery returns the ranking query and its arguments
+// A nil members list ranks everyone, otherwise only the listed users are ranked
+func RankedUsersQuery(since string, members []uuid.UUID) (string, []interface{}) {
+	if members == nil {
+		return fmt.Sprintf(rankedUsersSQL, ""), []interface{}{since}
+	}
+	return fmt.Sprintf(rankedUsersSQL, "AND user_id IN ?"), []interface{}{since, members}
+}
+
+// RankedUser is a row of the ranking query
+type RankedUser struct {
+	UserID     uuid.UUID
+	TotalHours float32
+	Rank       int
+	Position   int
+}
+
+// UserRank returns the user's rank for time tracked on or after the given local date
+// Users without tracked time are ranked after everyone who has some
+func UserRank(db *gorm.DB, userID uuid.UUID, since string) (int, error) {
+	query, args := RankedUsersQuery(since, nil)
+
+	var ranked []RankedUser
+	if err := db.Raw(`SELECT * FROM (`+query+`) ranked WHERE user_id = ?`, append(args, userID)...).Scan(&ranked).Error; err != nil {
+		return 0, err
+	}
+	if len(ranked) > 0 {
+		return ranked[0].Rank, nil
+	}
+
+	var total int64
+	if err := db.Raw(`SELECT COUNT(*) FROM (`+query+`) ranked`, args...).Scan(&total).Error; err != nil {
+		return 0, err
+	}
+	return int(total) + 1, nil
+}
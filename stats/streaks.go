@@ -0,0 +1,111 @@
+package stats
+
+import (
+	"sort"
+	"time"
+	"time-tracker/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Streaks holds the streak figures derived from a user's active days
+type Streaks struct {
+	ActiveDays    int
+	CurrentStreak int
+	LongestStreak int
+	LongestStart  *time.Time
+	LongestEnd    *time.Time
+}
+
+// LoadActiveDays returns, per user, the local dates on which they tracked time
+// Dates come from the daily totals, so entries crossing midnight count towards every day they touch
+func LoadActiveDays(db *gorm.DB, userIDs []uuid.UUID) (map[uuid.UUID][]time.Time, error) {
+	var rows []struct {
+		UserID    uuid.UUID
+		LocalDate time.Time
+	}
+	if err := db.Model(&models.DailyUserTotal{}).
+		Select("user_id, local_date").
+		Where("user_id IN ? AND seconds > 0", userIDs).
+		Group("user_id, local_date").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	days := make(map[uuid.UUID][]time.Time, len(userIDs))
+	for _, row := range rows {
+		date := row.LocalDate
+		days[row.UserID] = append(days[row.UserID], time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC))
+	}
+	return days, nil
+}
+
+// CalculateStreaks computes the current and longest streak of consecutive active days
+// The current streak is only kept alive if the user tracked time today or yesterday in loc
+func CalculateStreaks(days []time.Time, loc *time.Location, now time.Time) Streaks {
+	return CalculateStreaksWithRestDays(days, nil, loc, now)
+}
+
+// CalculateStreaksWithRestDays is CalculateStreaks where inactive days for which isRestDay
+// returns true neither break nor extend a streak (e.g. weekends for a weekday goal)
+func CalculateStreaksWithRestDays(days []time.Time, isRestDay func(day time.Time) bool, loc *time.Location, now time.Time) Streaks {
+	sorted := make([]time.Time, len(days))
+	copy(sorted, days)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	dayMap := make(map[time.Time]bool, len(sorted))
+	for _, day := range sorted {
+		dayMap[day] = true
+	}
+
+	result := Streaks{ActiveDays: len(dayMap)}
+	if len(sorted) == 0 {
+		return result
+	}
+
+	skip := func(day time.Time) bool {
+		return !dayMap[day] && isRestDay != nil && isRestDay(day)
+	}
+
+	// Find the longest run of consecutive days
+	run := 0
+	var runStart time.Time
+	first, last := sorted[0], sorted[len(sorted)-1]
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		switch {
+		case dayMap[day]:
+			if run == 0 {
+				runStart = day
+			}
+			run++
+			if run > result.LongestStreak {
+				result.LongestStreak = run
+				start, end := runStart, day
+				result.LongestStart = &start
+				result.LongestEnd = &end
+			}
+		case skip(day):
+			continue
+		default:
+			run = 0
+		}
+	}
+
+	// Count back from today, or from yesterday if nothing has been tracked yet today
+	today := LocalDate(now, loc)
+	day := today
+	if !dayMap[day] {
+		day = today.AddDate(0, 0, -1)
+	}
+	for !day.Before(first) {
+		if dayMap[day] {
+			result.CurrentStreak++
+		} else if !skip(day) {
+			break
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return result
+}
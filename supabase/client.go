@@ -1,39 +1,28 @@
 package supabase
 
 import (
-	"log"
-	"os"
+	"net/http"
+	"time-tracker/config"
 )
 
 type Client struct {
 	URL        string
 	AnonKey    string
 	ServiceKey string
-}
-
-var SupabaseClient *Client
-
-func InitClient() {
-	url := os.Getenv("SUPABASE_URL")
-	anonKey := os.Getenv("SUPABASE_ANON_KEY")
-	serviceKey := os.Getenv("SUPABASE_SERVICE_ROLE_KEY")
-
-	if url == "" || anonKey == "" {
-		log.Fatal("SUPABASE_URL and SUPABASE_ANON_KEY environment variables are required")
-	}
-
-	SupabaseClient = &Client{
-		URL:        url,
-		AnonKey:    anonKey,
-		ServiceKey: serviceKey,
-	}
 
-	log.Println("Supabase client initialized successfully")
+	Bucket        string // Storage bucket for profile pictures
+	MaxUploadSize int64  // Largest accepted upload in bytes
+	HTTPClient    *http.Client
 }
 
-func GetClient() *Client {
-	if SupabaseClient == nil {
-		InitClient()
+// NewClient returns a client for the Supabase project and storage settings
+func NewClient(cfg config.Supabase, storage config.Storage) *Client {
+	return &Client{
+		URL:           cfg.URL,
+		AnonKey:       cfg.AnonKey,
+		ServiceKey:    cfg.ServiceRoleKey,
+		Bucket:        storage.Bucket,
+		MaxUploadSize: storage.MaxUploadSize,
+		HTTPClient:    &http.Client{Timeout: storage.Timeout},
 	}
-	return SupabaseClient
 }
@@ -12,16 +12,11 @@ import (
 	"github.com/google/uuid"
 )
 
-const (
-	ProfilePicturesBucket = "profile-pictures"
-	MaxFileSize           = 5 * 1024 * 1024 // 5MB
-)
-
 // UploadProfilePicture uploads a profile picture to Supabase Storage
 func (c *Client) UploadProfilePicture(userID uuid.UUID, file multipart.File, header *multipart.FileHeader, userToken string) (string, error) {
 	// Validate file size
-	if header.Size > MaxFileSize {
-		return "", fmt.Errorf("file size exceeds maximum allowed size of 5MB")
+	if header.Size > c.MaxUploadSize {
+		return "", fmt.Errorf("file size exceeds maximum allowed size of %s", formatSize(c.MaxUploadSize))
 	}
 
 	// Validate file type
@@ -52,7 +47,7 @@ func (c *Client) UploadProfilePicture(userID uuid.UUID, file multipart.File, hea
 	}
 
 	// Upload to Supabase Storage
-	uploadURL := fmt.Sprintf("%s/storage/v1/object/%s/%s", c.URL, ProfilePicturesBucket, filename)
+	uploadURL := fmt.Sprintf("%s/storage/v1/object/%s/%s", c.URL, c.Bucket, filename)
 
 	req, err := http.NewRequest("POST", uploadURL, bytes.NewReader(fileBytes))
 	if err != nil {
@@ -63,8 +58,7 @@ func (c *Client) UploadProfilePicture(userID uuid.UUID, file multipart.File, hea
 	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("apikey", c.AnonKey)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to upload file: %w", err)
 	}
@@ -76,7 +70,7 @@ func (c *Client) UploadProfilePicture(userID uuid.UUID, file multipart.File, hea
 	}
 
 	// Return public URL
-	publicURL := fmt.Sprintf("%s/storage/v1/object/public/%s/%s", c.URL, ProfilePicturesBucket, filename)
+	publicURL := fmt.Sprintf("%s/storage/v1/object/public/%s/%s", c.URL, c.Bucket, filename)
 	return publicURL, nil
 }
 
@@ -84,13 +78,13 @@ func (c *Client) UploadProfilePicture(userID uuid.UUID, file multipart.File, hea
 func (c *Client) DeleteProfilePicture(pictureURL string) error {
 	// Extract filename from URL
 	// Format: https://xxx.supabase.co/storage/v1/object/public/profile-pictures/filename
-	parts := strings.Split(pictureURL, "/storage/v1/object/public/"+ProfilePicturesBucket+"/")
+	parts := strings.Split(pictureURL, "/storage/v1/object/public/"+c.Bucket+"/")
 	if len(parts) != 2 {
 		return fmt.Errorf("invalid picture URL format")
 	}
 	filename := parts[1]
 
-	deleteURL := fmt.Sprintf("%s/storage/v1/object/%s/%s", c.URL, ProfilePicturesBucket, filename)
+	deleteURL := fmt.Sprintf("%s/storage/v1/object/%s/%s", c.URL, c.Bucket, filename)
 
 	req, err := http.NewRequest("DELETE", deleteURL, nil)
 	if err != nil {
@@ -100,8 +94,7 @@ func (c *Client) DeleteProfilePicture(pictureURL string) error {
 	req.Header.Set("Authorization", "Bearer "+c.ServiceKey)
 	req.Header.Set("apikey", c.ServiceKey)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
@@ -113,4 +106,15 @@ func (c *Client) DeleteProfilePicture(pictureURL string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// formatSize formats a byte count as whole megabytes or kilobytes where possible
+func formatSize(size int64) string {
+	switch {
+	case size >= 1024*1024 && size%(1024*1024) == 0:
+		return fmt.Sprintf("%dMB", size/(1024*1024))
+	case size >= 1024 && size%1024 == 0:
+		return fmt.Sprintf("%dKB", size/1024)
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
@@ -0,0 +1,55 @@
+package timesheets
+
+import (
+	"time"
+	"time-tracker/models"
+	"time-tracker/stats"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WeekStart returns the first day of the profile's week containing t as midnight UTC
+func WeekStart(profile models.Profile, t time.Time) time.Time {
+	return stats.LocalDate(profile.StartOfWeek(t), profile.Location())
+}
+
+// Bounds returns the instants a week starting on weekStart begins and ends in loc
+func Bounds(weekStart time.Time, loc *time.Location) (time.Time, time.Time) {
+	start := time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, loc)
+	return start, time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day()+7, 0, 0, 0, 0, loc)
+}
+
+// Entries scopes a time entry query to the user's entries starting within the week
+func Entries(userID uuid.UUID, weekStart time.Time, loc *time.Location) func(*gorm.DB) *gorm.DB {
+	from, to := Bounds(weekStart, loc)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ? AND start_time >= ? AND start_time < ?", userID, from, to)
+	}
+}
+
+// Locked reports whether the entry belongs to an approved timesheet
+func Locked(db *gorm.DB, entry models.TimeEntry) (bool, error) {
+	_, locked, err := LockedUntil(db, entry)
+	return locked, err
+}
+
+// LockedUntil returns the end of the approved timesheet's week the entry belongs to,
+// and false when it belongs to none
+// The entry's week is found from its local start date, so later changes to the
+// user's week start day do not unlock entries of weeks already approved
+func LockedUntil(db *gorm.DB, entry models.TimeEntry) (time.Time, bool, error) {
+	loc := stats.UserLocation(db, entry.UserID)
+	date := stats.LocalDate(entry.StartTime, loc)
+	var approved []models.Timesheet
+	err := db.Where("user_id = ? AND status = ? AND week_start <= ? AND week_start > ?",
+		entry.UserID, models.TimesheetApproved,
+		date.Format(stats.DateFormat), date.AddDate(0, 0, -7).Format(stats.DateFormat)).
+		Limit(1).
+		Find(&approved).Error
+	if err != nil || len(approved) == 0 {
+		return time.Time{}, false, err
+	}
+	_, end := Bounds(stats.LocalDate(approved[0].WeekStart, time.UTC), loc)
+	return end, true, nil
+}
@@ -0,0 +1,76 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"time-tracker/models"
+
+	"gorm.io/gorm"
+)
+
+// Prefix starts every personal access token, telling them apart from JWTs
+const Prefix = "ttp_"
+
+// displayPrefixLength is how much of a token is kept to recognize it in listings
+const displayPrefixLength = len(Prefix) + 6
+
+// lastUsedResolution limits last-used updates to one write per token per minute
+const lastUsedResolution = time.Minute
+
+// ErrInvalidToken is returned for unknown, revoked and expired tokens
+var ErrInvalidToken = errors.New("invalid or expired personal access token")
+
+// IsPersonalAccessToken reports whether a bearer token is a personal access token
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
+
+// Generate returns a new random token, the hash to store for it and its display prefix
+func Generate() (token, hash, prefix string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	token = Prefix + hex.EncodeToString(buf)
+	return token, Hash(token), token[:displayPrefixLength], nil
+}
+
+// Hash returns the hex-encoded SHA-256 hash of a token
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Scopes splits a token's stored scopes
+func Scopes(pat models.PersonalAccessToken) []string {
+	return strings.Fields(pat.Scopes)
+}
+
+// Authenticate looks up an unexpired token and records that it was used
+func Authenticate(db *gorm.DB, token string) (models.PersonalAccessToken, error) {
+	var pat models.PersonalAccessToken
+	err := db.Where("token_hash = ?", Hash(token)).First(&pat).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return pat, ErrInvalidToken
+	}
+	if err != nil {
+		return pat, err
+	}
+
+	now := time.Now()
+	if pat.ExpiresAt != nil && !now.Before(*pat.ExpiresAt) {
+		return pat, ErrInvalidToken
+	}
+
+	if pat.LastUsedAt == nil || now.Sub(*pat.LastUsedAt) >= lastUsedResolution {
+		if err := db.Model(&models.PersonalAccessToken{}).Where("id = ?", pat.ID).Update("last_used_at", now).Error; err != nil {
+			return pat, err
+		}
+		pat.LastUsedAt = &now
+	}
+	return pat, nil
+}
@@ -0,0 +1,112 @@
+package workspaces
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+	"time-tracker/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InvitationTTL is how long an invitation can be accepted
+const InvitationTTL = 7 * 24 * time.Hour
+
+// roleRank orders the roles by privilege; unknown roles rank 0
+var roleRank = map[string]int{
+	models.WorkspaceRoleViewer: 1,
+	models.WorkspaceRoleMember: 2,
+	models.WorkspaceRoleAdmin:  3,
+	models.WorkspaceRoleOwner:  4,
+}
+
+// AtLeast reports whether role grants at least the privileges of min
+func AtLeast(role, min string) bool {
+	return roleRank[role] > 0 && roleRank[role] >= roleRank[min]
+}
+
+// Outranks reports whether role is strictly more privileged than other
+func Outranks(role, other string) bool {
+	return roleRank[role] > roleRank[other]
+}
+
+// Role returns the user's role in the workspace, or "" if they are not a member
+func Role(db *gorm.DB, workspaceID, userID uuid.UUID) (string, error) {
+	var member models.WorkspaceMember
+	err := db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// ProjectRole returns the user's role on a project: owner of their personal projects,
+// their workspace role on shared projects and "" when they have no access
+func ProjectRole(db *gorm.DB, project models.Project, userID uuid.UUID) (string, error) {
+	if project.WorkspaceID == nil {
+		if project.UserID == userID {
+			return models.WorkspaceRoleOwner, nil
+		}
+		return "", nil
+	}
+	return Role(db, *project.WorkspaceID, userID)
+}
+
+// AccessibleProjects scopes a project query to the user's personal projects
+// and the projects of every workspace they belong to
+func AccessibleProjects(userID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(workspace_id IS NULL AND user_id = ?) OR workspace_id IN (?)",
+			userID, db.Session(&gorm.Session{NewDB: true}).Model(&models.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", userID))
+	}
+}
+
+// ManagedProjectIDs returns a subquery selecting the projects of workspaces the user administers
+// Use it to let admins see everyone's time entries on shared projects
+func ManagedProjectIDs(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	managed := db.Session(&gorm.Session{NewDB: true}).Model(&models.WorkspaceMember{}).
+		Select("workspace_id").
+		Where("user_id = ? AND role IN ?", userID, []string{models.WorkspaceRoleOwner, models.WorkspaceRoleAdmin})
+	return db.Session(&gorm.Session{NewDB: true}).Model(&models.Project{}).Select("id").Where("workspace_id IN (?)", managed)
+}
+
+// NewInvitationToken returns a random accept token and the hash to store for it
+func NewInvitationToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hex-encoded SHA-256 hash of an accept token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ManagedUserIDs returns a subquery selecting the users the manager manages:
+// members below them in a workspace they administer
+func ManagedUserIDs(db *gorm.DB, managerID uuid.UUID) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Table("workspace_members AS members").
+		Select("members.user_id").
+		Joins("JOIN workspace_members AS managers ON managers.workspace_id = members.workspace_id").
+		Where("managers.user_id = ? AND members.user_id <> ?", managerID, managerID).
+		Where("(managers.role = ? AND members.role <> ?) OR (managers.role = ? AND members.role IN ?)",
+			models.WorkspaceRoleOwner, models.WorkspaceRoleOwner,
+			models.WorkspaceRoleAdmin, []string{models.WorkspaceRoleMember, models.WorkspaceRoleViewer})
+}
+
+// Manages reports whether the manager administers a workspace where the user is a member below them
+func Manages(db *gorm.DB, managerID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := ManagedUserIDs(db, managerID).Where("members.user_id = ?", userID).Count(&count).Error
+	return count > 0, err
+}